@@ -2,15 +2,28 @@ package main
 
 import (
 	"os"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/history"
 )
 
-func runAttach(args []string) error {
+// runAttach attaches to the session args[0], created in --dir if given.
+// Read-only attaches only watch a running session, so they neither create
+// it nor count as a use in the history.
+func runAttach(args []string, readOnly bool) error {
 	b, err := loadBackend(true)
 	if err != nil {
 		return err
 	}
 
 	name := args[0]
+	if readOnly {
+		cmd, err := backend.ReadOnlyAttachCommand(b, name)
+		if err != nil {
+			return err
+		}
+		return backend.Exec(cmd)
+	}
 	dir := ""
 
 	for i := 1; i < len(args); i++ {
@@ -25,5 +38,6 @@ func runAttach(args []string) error {
 			return err
 		}
 	}
+	history.Record(name, backend.OwnerOf(b, name).Name())
 	return b.Attach(name)
 }
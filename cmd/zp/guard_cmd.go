@@ -7,6 +7,7 @@ import (
 
 	"github.com/nerveband/zpick/internal/guard"
 	"github.com/nerveband/zpick/internal/hook"
+	"github.com/nerveband/zpick/internal/shell"
 )
 
 func runGuard(args []string) error {
@@ -74,8 +75,8 @@ func runGuard(args []string) error {
 	if err != nil {
 		return err
 	}
-	if cmd != "" {
-		fmt.Print(cmd)
+	if !cmd.IsZero() {
+		fmt.Print(cmd.Render(shell.Current()))
 	}
 	return nil
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nerveband/zpick/internal/history"
+)
+
+// HistoryResult is the JSON output format for `zp history --json`.
+type HistoryResult struct {
+	Entries []history.Entry `json:"entries"` // oldest first
+	Count   int             `json:"count"`
+}
+
+// runHistory prints the log of sessions attached to through zp.
+func runHistory() error {
+	entries, err := history.Read()
+	if err != nil {
+		return err
+	}
+
+	if hasJSONFlag() {
+		if entries == nil {
+			entries = []history.Entry{}
+		}
+		out, err := json.MarshalIndent(HistoryResult{Entries: entries, Count: len(entries)}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("  no history yet")
+		return nil
+	}
+	for _, e := range entries {
+		fmt.Printf("  %s  %s [%s]\n", e.Time.Local().Format("2006-01-02 15:04"), e.Name, e.Backend)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/history"
+)
+
+// Golden test: the JSON contract for history --json.
+func TestHistoryJSONContract(t *testing.T) {
+	result := HistoryResult{
+		Entries: []history.Entry{{Time: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC), Name: "api", Backend: "tmux"}},
+		Count:   1,
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"entries":[{"time":"2026-01-01T09:00:00Z","name":"api","backend":"tmux"}],"count":1}`
+	if string(b) != want {
+		t.Errorf("history JSON = %s\nwant %s", b, want)
+	}
+}
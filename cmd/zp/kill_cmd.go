@@ -1,9 +1,25 @@
 package main
 
+import (
+	"fmt"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
 func runKill(name string) error {
 	b, err := loadBackend(true)
 	if err != nil {
 		return err
 	}
+	if !hasFlag("--force") {
+		protected, err := backend.ReadProtected()
+		if err != nil {
+			return err
+		}
+		owner := backend.OwnerOf(b, name).Name()
+		if backend.IsProtected(protected, backend.Session{Name: name, Backend: owner}, owner) {
+			return fmt.Errorf("%q is protected (use --force, or zp unprotect %s)", name, name)
+		}
+	}
 	return b.Kill(name)
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/history"
+	"github.com/nerveband/zpick/internal/picker"
+)
+
+// runLast goes back to the session used before the current one: from
+// inside a session it switches like the picker does, otherwise it attaches
+// to the session used last.
+func runLast() error {
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	sessions, err := b.FastList()
+	if err != nil {
+		return err
+	}
+	entries, err := history.Read()
+	if err != nil {
+		return err
+	}
+
+	inSession := b.InSession() && os.Getenv("ZPICK") == ""
+	var current string
+	if inSession {
+		currentBackend := b.Name()
+		if agg, ok := b.(*backend.Aggregate); ok {
+			currentBackend = agg.CurrentBackend()
+		}
+		current = history.Key(currentBackend, b.CurrentSessionName())
+	}
+
+	s, ok := history.Previous(entries, sessions, b.Name(), current)
+	if !ok {
+		return fmt.Errorf("no previous session to go back to")
+	}
+	if inSession {
+		return picker.Switch(b, s.Backend, s.Name)
+	}
+
+	o := backend.OwnerOf(b, s.Name)
+	if agg, ok := b.(*backend.Aggregate); ok && s.Backend != "" {
+		if m := agg.Member(s.Backend); m != nil {
+			o = m
+		}
+	}
+	history.Record(s.Name, o.Name())
+	return o.Attach(s.Name)
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/history"
 )
 
 // ListResult is the JSON output format for `zpick list --json`.
@@ -14,6 +17,14 @@ type ListResult struct {
 	Count          int               `json:"count"`
 	ZmoshVersion   string            `json:"zmosh_version,omitempty"`
 	BackendVersion string            `json:"backend_version,omitempty"`
+
+	// Capabilities says which session fields hold real data.
+	Capabilities backend.Capabilities `json:"capabilities"`
+
+	// BackendVersions and BackendCapabilities are set in aggregated mode,
+	// keyed by backend name.
+	BackendVersions     map[string]string               `json:"backend_versions,omitempty"`
+	BackendCapabilities map[string]backend.Capabilities `json:"backend_capabilities,omitempty"`
 }
 
 func runList() error {
@@ -25,16 +36,33 @@ func runList() error {
 		return err
 	}
 
+	mode := flagValue("--sort")
+	if mode == "" {
+		mode = backend.ReadSortMode()
+	} else if !slices.Contains(backend.SortModes, mode) {
+		return fmt.Errorf("invalid sort mode %q (valid: %s)", mode, strings.Join(backend.SortModes, ", "))
+	}
+
+	sessions, err := b.List()
+	if err != nil {
+		return err
+	}
+	backend.SortSessions(sessions, mode, history.Recency(b.Name()))
+	backend.AddProcessInfo(sessions)
+
 	if jsonOutput {
-		sessions, err := b.List()
-		if err != nil {
-			return err
-		}
 		result := ListResult{
-			Sessions: sessions,
-			Count:    len(sessions),
+			Sessions:     sessions,
+			Count:        len(sessions),
+			Capabilities: b.Capabilities(),
 		}
-		if ver, err := b.Version(); err == nil {
+		if agg, ok := b.(*backend.Aggregate); ok {
+			result.BackendVersions = agg.Versions()
+			result.BackendCapabilities = make(map[string]backend.Capabilities)
+			for _, m := range agg.Members() {
+				result.BackendCapabilities[m.Name()] = m.Capabilities()
+			}
+		} else if ver, err := b.Version(); err == nil {
 			result.BackendVersion = ver
 			// Keep zmosh_version for backwards compat
 			if b.Name() == "zmosh" || b.Name() == "zmx" {
@@ -49,22 +77,46 @@ func runList() error {
 		return nil
 	}
 
-	sessions, err := b.List()
-	if err != nil {
-		return err
-	}
-
 	if len(sessions) == 0 {
 		fmt.Println("  no sessions")
 		return nil
 	}
 
 	for _, s := range sessions {
-		status := "."
-		if s.Active {
-			status = "*"
-		}
-		fmt.Printf("  %s%s  (%d clients)  %s\n", status, s.Name, s.Clients, s.StartedIn)
+		fmt.Println(formatSession(s, sessionCaps(b, s)))
 	}
 	return nil
 }
+
+// formatSession renders one line of the human-readable list, leaving out
+// fields the owning backend can't report.
+func formatSession(s backend.Session, caps backend.Capabilities) string {
+	status := "."
+	if s.Active {
+		status = "*"
+	}
+	line := "  " + status + s.Name
+	if s.Backend != "" {
+		line += "  [" + s.Backend + "]"
+	}
+	if caps.ClientCounts {
+		line += fmt.Sprintf("  (%d clients)", s.Clients)
+	}
+	if caps.SessionDirs {
+		line += "  " + s.StartedIn
+	}
+	if s.Process != nil {
+		line += "  " + s.Process.Summary()
+	}
+	return line
+}
+
+// sessionCaps returns the capabilities of the backend that owns s.
+func sessionCaps(b backend.Backend, s backend.Session) backend.Capabilities {
+	if agg, ok := b.(*backend.Aggregate); ok && s.Backend != "" {
+		if m := agg.Member(s.Backend); m != nil {
+			return m.Capabilities()
+		}
+	}
+	return b.Capabilities()
+}
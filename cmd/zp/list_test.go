@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
 )
@@ -58,3 +59,93 @@ func TestListJSONContract(t *testing.T) {
 		}
 	}
 }
+
+// Timestamps are emitted when known and left out when the backend can't report them.
+func TestListJSONTimestamps(t *testing.T) {
+	created := time.Date(2026, 2, 21, 10, 0, 0, 0, time.UTC)
+	result := ListResult{
+		Sessions: []backend.Session{
+			{Name: "tmux", CreatedAt: created, LastActivity: created.Add(time.Hour)},
+			{Name: "shpool"},
+		},
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Sessions []map[string]interface{} `json:"sessions"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw.Sessions[0]["created_at"] != "2026-02-21T10:00:00Z" || raw.Sessions[0]["last_activity"] == nil {
+		t.Errorf("timestamps missing: %v", raw.Sessions[0])
+	}
+	for _, field := range []string{"created_at", "last_activity", "task_ended_at", "task_exit_code"} {
+		if _, ok := raw.Sessions[1][field]; ok {
+			t.Errorf("%s should be omitted when unknown", field)
+		}
+	}
+}
+
+// In aggregated mode each session carries its backend and versions are keyed per backend.
+func TestListJSONAggregated(t *testing.T) {
+	result := ListResult{
+		Sessions: []backend.Session{
+			{Name: "work", StartedIn: "~", Backend: "tmux"},
+			{Name: "play", StartedIn: "~", Backend: "zmosh"},
+		},
+		Count:           2,
+		BackendVersions: map[string]string{"tmux": "3.4", "zmosh": "0.4.2"},
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw struct {
+		Sessions        []map[string]interface{} `json:"sessions"`
+		BackendVersions map[string]string        `json:"backend_versions"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw.Sessions[0]["backend"] != "tmux" || raw.Sessions[1]["backend"] != "zmosh" {
+		t.Errorf("sessions should carry their backend, got %v", raw.Sessions)
+	}
+	if raw.BackendVersions["zmosh"] != "0.4.2" {
+		t.Errorf("backend_versions = %v", raw.BackendVersions)
+	}
+}
+
+// Capabilities are always present so consumers know which session fields are real.
+func TestListJSONCapabilities(t *testing.T) {
+	result := ListResult{Capabilities: backend.Capabilities{SessionDirs: true}}
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Capabilities map[string]bool `json:"capabilities"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if !raw.Capabilities["session_dirs"] || raw.Capabilities["client_counts"] {
+		t.Errorf("capabilities = %v", raw.Capabilities)
+	}
+}
+
+func TestFormatSessionHidesUnsupportedFields(t *testing.T) {
+	s := backend.Session{Name: "work", Clients: 2, StartedIn: "~/work", Active: true}
+
+	got := formatSession(s, backend.Capabilities{SessionDirs: true, ClientCounts: true})
+	if got != "  *work  (2 clients)  ~/work" {
+		t.Errorf("formatSession() = %q", got)
+	}
+	// zellij and shpool know neither
+	if got := formatSession(s, backend.Capabilities{}); got != "  *work" {
+		t.Errorf("formatSession() without capabilities = %q", got)
+	}
+}
@@ -3,8 +3,13 @@ package main
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/custom"
+	"github.com/nerveband/zpick/internal/backend/plugin"
+	"github.com/nerveband/zpick/internal/remote"
 	"github.com/nerveband/zpick/internal/update"
 
 	// Register all backends via init()
@@ -18,7 +23,15 @@ import (
 var version = "dev"
 
 func main() {
-	if len(os.Args) < 2 {
+	// Register backends from backends.conf and zp-backend-* executables
+	// alongside the built-ins. A broken backends.conf is only reported
+	// where someone reads it, not to the hook or to scripts.
+	if err := custom.RegisterAll(); err != nil && reportsConfigErrors(os.Args[1:]) {
+		fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+	}
+	plugin.RegisterAll()
+
+	if len(os.Args) < 2 || isPickerFlag(os.Args[1]) {
 		if err := runPicker(); err != nil {
 			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
 			os.Exit(1)
@@ -31,6 +44,10 @@ func main() {
 		updateCh = update.CheckAsync(version)
 	}
 
+	// Positional arguments of subcommands, without the backend selection
+	// flags that loadBackend reads
+	args := stripBackendFlags(os.Args[2:])
+
 	switch os.Args[1] {
 	case "list":
 		if err := runList(); err != nil {
@@ -43,20 +60,80 @@ func main() {
 			os.Exit(1)
 		}
 	case "attach":
-		if len(os.Args) < 3 {
-			fmt.Fprintln(os.Stderr, "usage: zp attach <name> [--dir <path>]")
+		readOnly := slices.Contains(args, "--read-only")
+		args = slices.DeleteFunc(args, func(arg string) bool { return arg == "--read-only" })
+		if len(args) < 1 {
+			fmt.Fprintln(os.Stderr, "usage: zp attach <name> [--dir <path>] [--read-only]")
 			os.Exit(1)
 		}
-		if err := runAttach(os.Args[2:]); err != nil {
+		if err := runAttach(args, readOnly); err != nil {
 			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
 			os.Exit(1)
 		}
 	case "kill":
-		if len(os.Args) < 3 {
-			fmt.Fprintln(os.Stderr, "usage: zp kill <name>")
+		name := firstPositional(args)
+		if name == "" {
+			fmt.Fprintln(os.Stderr, "usage: zp kill <name> [--force]")
+			os.Exit(1)
+		}
+		if err := runKill(name); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "rename":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "usage: zp rename <old> <new>")
+			os.Exit(1)
+		}
+		if err := runRename(args[0], args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "prune":
+		if err := runPrune(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "protect":
+		if err := runProtect(args); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "unprotect":
+		if len(args) < 1 {
+			fmt.Fprintln(os.Stderr, "usage: zp unprotect <name>")
+			os.Exit(1)
+		}
+		if err := runUnprotect(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "pin":
+		if err := runPin(args); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "unpin":
+		if len(args) < 1 {
+			fmt.Fprintln(os.Stderr, "usage: zp unpin <name>")
+			os.Exit(1)
+		}
+		if err := runUnpin(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "last":
+		if err := runLast(); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "history":
+		if err := runHistory(); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
 			os.Exit(1)
 		}
-		if err := runKill(os.Args[2]); err != nil {
+	case "popup":
+		if err := runPopup(os.Args[2:]); err != nil {
 			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
 			os.Exit(1)
 		}
@@ -106,19 +183,110 @@ func main() {
 	}
 }
 
+// loadBackend returns the configured backend, adjusted by the command line:
+// --all aggregates every installed backend, --host <h> runs the backend on
+// a remote host over ssh, and --remote aggregates every configured host.
 func loadBackend(interactive bool) (backend.Backend, error) {
+	local := func() (backend.Backend, error) { return backend.Load(interactive) }
+
+	if host := flagValue("--host"); host != "" {
+		return remote.Load(remote.FindHost(host), local)
+	}
+
+	if hasFlag("--remote") {
+		members, err := remote.LoadAll(local)
+		if err != nil {
+			return nil, err
+		}
+		if hasFlag("--all") {
+			if all, err := backend.LoadAll(); err == nil {
+				members = append(all.(*backend.Aggregate).Members(), members...)
+			}
+		}
+		return backend.NewAggregate(members), nil
+	}
+
+	if hasFlag("--all") {
+		return backend.LoadAll()
+	}
 	return backend.Load(interactive)
 }
 
+// isPickerFlag reports whether arg is a flag that runs the picker
+// rather than a subcommand.
+func isPickerFlag(arg string) bool {
+	switch arg {
+	case "--all", "--host", "--remote":
+		return true
+	}
+	return false
+}
+
+// stripBackendFlags returns args without the backend selection flags
+// (--all, --remote, and --host with its value), leaving the positional
+// arguments and flags that belong to the subcommand.
+func stripBackendFlags(args []string) []string {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--all", "--remote":
+		case "--host":
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return rest
+}
+
+// firstPositional returns the first of args that isn't a flag, for
+// subcommands whose flags take no value, or "" if there is none.
+func firstPositional(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
+
+// flagValue returns the value following flag on the command line, or empty.
+func flagValue(flag string) string {
+	for i, arg := range os.Args[1:] {
+		if arg == flag && i+2 < len(os.Args) {
+			return os.Args[i+2]
+		}
+	}
+	return ""
+}
+
 func hasJSONFlag() bool {
-	for _, arg := range os.Args[2:] {
-		if arg == "--json" {
+	return hasFlag("--json")
+}
+
+// hasFlag reports whether flag appears anywhere in the command line.
+func hasFlag(flag string) bool {
+	for _, arg := range os.Args[1:] {
+		if arg == flag {
 			return true
 		}
 	}
 	return false
 }
 
+// reportsConfigErrors reports whether the command run with args should
+// warn about a broken backends.conf. The prompt hook's commands and --json
+// output stay quiet, and zp check shows the error in its own report.
+func reportsConfigErrors(args []string) bool {
+	if len(args) > 0 {
+		switch args[0] {
+		case "check", "guard", "autorun", "resume", "version":
+			return false
+		}
+	}
+	return !slices.Contains(args, "--json")
+}
+
 func shouldCheckUpdates(args []string) bool {
 	if len(args) == 0 {
 		return false
@@ -140,10 +308,27 @@ func printUsage() {
 
 Usage:
   zp              Interactive TUI picker (default)
-  zp list         List sessions (--json for machine-readable)
+  zp --all        Picker across every installed backend
+  zp --host <h>   Picker for sessions on a remote host over ssh
+  zp --remote     Picker across every configured remote host
+  zp list         List sessions (--json for machine-readable, --all for every backend,
+                  --sort default|recent|name|active|dir)
   zp check        Check dependencies (--json for machine-readable)
-  zp attach <n>   Attach or create session
-  zp kill <name>  Kill a session
+  zp attach <n>   Attach or create session (--read-only to watch a running one, tmux)
+  zp kill <name>  Kill a session (--force to kill a protected one)
+  zp rename <old> <new>  Rename a session (tmux, zellij)
+  zp prune        Kill sessions by policy: --idle-for 7d, --detached, --name-glob 'scratch-*',
+                  --dead (zmx/zmosh), --exclude <glob>, --dry-run, --force (include protected)
+  zp pin <name>   Pin a session to the first picker keys (--dir <path> to recreate it there,
+                  no name to list pins)
+  zp unpin <name> Unpin a session
+  zp protect <name>    Protect a session from bulk kills, zp kill and zp prune
+                       (no name to list protected sessions)
+  zp unprotect <name>  Remove a session's protection
+  zp last         Go back to the previous session (switches when run inside one)
+  zp history      Sessions attached to through zp, oldest first (--json for machine-readable)
+  zp popup        Open the picker in a tmux popup or zellij floating pane and switch from it
+                  (--bind tmux|zellij prints a key binding for your config)
   zp guard        Session guard for AI coding tools
   zp install-hook Add shell hook to .zshrc/.bashrc/.config/fish
   zp upgrade      Upgrade to the latest version
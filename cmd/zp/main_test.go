@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStripBackendFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--host", "build1", "foo"}, []string{"foo"}},
+		{[]string{"--all", "foo", "--dir", "/tmp"}, []string{"foo", "--dir", "/tmp"}},
+		{[]string{"foo", "--remote", "--force"}, []string{"foo", "--force"}},
+		{[]string{"old", "--host", "build1", "new"}, []string{"old", "new"}},
+	}
+	for _, tt := range tests {
+		if got := stripBackendFlags(tt.args); !slices.Equal(got, tt.want) {
+			t.Errorf("stripBackendFlags(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestReportsConfigErrors(t *testing.T) {
+	for _, args := range [][]string{nil, {"--all"}, {"list"}, {"attach", "foo"}} {
+		if !reportsConfigErrors(args) {
+			t.Errorf("reportsConfigErrors(%q) = false, want true", args)
+		}
+	}
+	for _, args := range [][]string{{"resume"}, {"guard", "--", "claude"}, {"autorun"}, {"check"}, {"list", "--json"}} {
+		if reportsConfigErrors(args) {
+			t.Errorf("reportsConfigErrors(%q) = true, want false", args)
+		}
+	}
+}
+
+func TestKillNameEitherFlagOrder(t *testing.T) {
+	for _, argv := range [][]string{
+		{"--force", "build"},
+		{"build", "--force"},
+		{"--host", "build1", "--force", "build"},
+	} {
+		if got := firstPositional(stripBackendFlags(argv)); got != "build" {
+			t.Errorf("kill name from %q = %q, want build", argv, got)
+		}
+	}
+	if got := firstPositional([]string{"--force"}); got != "" {
+		t.Errorf("firstPositional(--force) = %q, want empty", got)
+	}
+}
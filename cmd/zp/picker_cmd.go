@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/nerveband/zpick/internal/picker"
+	"github.com/nerveband/zpick/internal/shell"
 )
 
 func runPicker() error {
@@ -15,8 +16,8 @@ func runPicker() error {
 	if err != nil {
 		return err
 	}
-	if cmd != "" {
-		fmt.Print(cmd)
+	if !cmd.IsZero() {
+		fmt.Print(cmd.Render(shell.Current()))
 	}
 	return nil
 }
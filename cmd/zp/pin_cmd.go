@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/picker"
+)
+
+// runPin pins a session so the picker always lists it first, on the same
+// key, or lists the pins when no name is given.
+func runPin(args []string) error {
+	if len(args) == 0 || args[0] == "--list" {
+		pins, err := backend.ReadPins()
+		if err != nil {
+			return err
+		}
+		picker.LoadKeyMode(backend.ReadKeyMode())
+		for i, p := range pins {
+			// Labels assume every pin applies to the picker's backend.
+			line := fmt.Sprintf("%c  %s", picker.KeyForIndex(i), p.Name)
+			if p.Backend != "" {
+				line += "  [" + p.Backend + "]"
+			}
+			if p.Dir != "" {
+				line += "  " + p.Dir
+			}
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	p := backend.NewPin(backend.OwnerOf(b, name), name)
+	for i := 1; i < len(args); i++ {
+		if args[i] == "--dir" && i+1 < len(args) {
+			dir, err := filepath.Abs(args[i+1])
+			if err != nil {
+				return err
+			}
+			p.Dir = dir
+			break
+		}
+	}
+	if err := backend.AddPin(p); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "  pinned %q\n", name)
+	return nil
+}
+
+// runUnpin removes a session's pin.
+func runUnpin(name string) error {
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	ok, err := backend.RemovePin(name, backend.OwnerOf(b, name).Name())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%q isn't pinned", name)
+	}
+	fmt.Fprintf(os.Stderr, "  unpinned %q\n", name)
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/picker"
+)
+
+// popupEnv marks the zp running inside the popup, so `zp popup` runs the
+// picker there instead of opening another popup.
+const popupEnv = "ZPICK_POPUP"
+
+// runPopup opens the picker in a popup over the current tmux or zellij
+// session, or runs it when already inside one. `zp popup --bind <backend>`
+// prints the config snippet that binds a key to the popup.
+func runPopup(args []string) error {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--bind" {
+			if i+1 >= len(args) {
+				return fmt.Errorf("--bind requires a backend (tmux or zellij)")
+			}
+			return printPopupBinding(args[i+1])
+		}
+		rest = append(rest, args[i])
+	}
+
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	if os.Getenv(popupEnv) != "" {
+		return runPopupPicker(b)
+	}
+
+	current := b
+	if agg, ok := b.(*backend.Aggregate); ok {
+		current = agg.Member(agg.CurrentBackend())
+	}
+	if current == nil || !current.InSession() {
+		return fmt.Errorf("zp popup runs inside a tmux or zellij session")
+	}
+	p, ok := current.(backend.Popup)
+	if !ok {
+		return backend.Unsupported(current, "open a popup")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "zp"
+	}
+	argv := p.PopupArgs(popupArgv(exe, rest))
+	c := exec.Command(argv[0], argv[1:]...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return c.Run()
+}
+
+// popupArgv returns the command run inside the popup: zp popup again,
+// marked as inside it and with the same backend flags.
+func popupArgv(exe string, args []string) []string {
+	return append([]string{"env", popupEnv + "=1", exe, "popup"}, args...)
+}
+
+// runPopupPicker runs the picker inside the popup. Picking a session
+// switches the client underneath in place; nothing evals the popup's
+// output, so a backend that can't do that has its detach run here.
+func runPopupPicker(b backend.Backend) error {
+	cmd, err := picker.Run(b, version)
+	if err != nil || cmd.IsZero() {
+		return err
+	}
+	c := exec.Command(cmd.Args[0], cmd.Args[1:]...)
+	c.Dir = cmd.Dir
+	c.Env = append(os.Environ(), cmd.Env...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return c.Run()
+}
+
+// printPopupBinding prints the config snippet binding a key to the popup
+// in the named backend.
+func printPopupBinding(name string) error {
+	b, err := backend.New(name)
+	if err != nil {
+		return err
+	}
+	p, ok := b.(backend.Popup)
+	if !ok {
+		return backend.Unsupported(b, "open a popup")
+	}
+	fmt.Print(p.PopupBinding(popupArgv("zp", nil)))
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPopupArgv(t *testing.T) {
+	got := strings.Join(popupArgv("/usr/local/bin/zp", []string{"--all"}), " ")
+	want := "env ZPICK_POPUP=1 /usr/local/bin/zp popup --all"
+	if got != want {
+		t.Errorf("popupArgv() = %q, want %q", got, want)
+	}
+}
+
+func TestPrintPopupBindingUnsupported(t *testing.T) {
+	if err := printPopupBinding("shpool"); err == nil {
+		t.Error("printPopupBinding(shpool) should fail: shpool has no popups")
+	}
+	if err := printPopupBinding("nope"); err == nil {
+		t.Error("printPopupBinding(nope) should fail")
+	}
+}
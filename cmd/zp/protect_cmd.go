@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// runProtect protects a session from bulk kills, `zp kill` and `zp prune`,
+// or lists the protected sessions when no name is given.
+func runProtect(args []string) error {
+	if len(args) == 0 || args[0] == "--list" {
+		protected, err := backend.ReadProtected()
+		if err != nil {
+			return err
+		}
+		for _, p := range protected {
+			if p.Backend != "" {
+				fmt.Printf("%s  [%s]\n", p.Name, p.Backend)
+			} else {
+				fmt.Println(p.Name)
+			}
+		}
+		return nil
+	}
+
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if err := backend.Protect(name, backend.OwnerOf(b, name).Name()); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "  protected %q\n", name)
+	return nil
+}
+
+// runUnprotect removes a session's protection.
+func runUnprotect(name string) error {
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	ok, err := backend.Unprotect(name, backend.OwnerOf(b, name).Name())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%q isn't protected", name)
+	}
+	fmt.Fprintf(os.Stderr, "  unprotected %q\n", name)
+	return nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/history"
+)
+
+// pruneRule selects the sessions `zp prune` removes. A session has to pass
+// every filter that is set.
+type pruneRule struct {
+	idleFor  time.Duration // unused for at least this long
+	detached bool          // no clients attached
+	nameGlob string        // name matches this glob
+	dead     bool          // server is gone; only backends that can tell
+	exclude  []string      // globs of names that are never pruned
+	pins     []backend.Pin // pinned sessions are never pruned
+
+	// protected sessions are skipped unless forced.
+	protected []backend.Protected
+	force     bool
+}
+
+// pruneTarget is a session chosen for pruning and the backend that owns it.
+type pruneTarget struct {
+	b backend.Backend
+	s backend.Session
+}
+
+func runPrune(args []string) error {
+	rule, dryRun, err := parsePruneArgs(args)
+	if err != nil {
+		return err
+	}
+	if rule.idleFor == 0 && !rule.detached && rule.nameGlob == "" && !rule.dead {
+		return fmt.Errorf("nothing to prune by: give --idle-for, --detached, --name-glob or --dead")
+	}
+	rule.pins, _ = backend.ReadPins()
+	if !rule.force {
+		if rule.protected, err = backend.ReadProtected(); err != nil {
+			return err
+		}
+	}
+
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	members := []backend.Backend{b}
+	agg, aggregated := b.(*backend.Aggregate)
+	if aggregated {
+		members = agg.Members()
+	}
+
+	now := time.Now()
+	lastUsed := history.Recency(b.Name())
+	var targets []pruneTarget
+	for _, m := range members {
+		var sessions []backend.Session
+		if rule.dead {
+			dc, ok := m.(backend.DeadCleaner)
+			if !ok {
+				continue // can't tell dead sessions apart
+			}
+			sessions, err = dc.DeadSessions()
+		} else {
+			if rule.detached && !m.Capabilities().ClientCounts {
+				fmt.Fprintf(os.Stderr, "  %s doesn't report attached clients, skipping its sessions\n", m.Name())
+				continue
+			}
+			sessions, err = m.List()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %s: %v\n", m.Name(), err)
+			continue
+		}
+		for _, s := range sessions {
+			if aggregated {
+				s.Backend = m.Name()
+			}
+			if m.InSession() && s.Name == m.CurrentSessionName() {
+				continue // never the session we're running in
+			}
+			if rule.matches(s, m.Name(), now, lastUsed) {
+				targets = append(targets, pruneTarget{m, s})
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("  nothing to prune")
+		return nil
+	}
+
+	failed := 0
+	for _, t := range targets {
+		label := t.s.Name
+		if t.s.Backend != "" {
+			label += " [" + t.s.Backend + "]"
+		}
+		if dryRun {
+			fmt.Printf("  would prune %s\n", label)
+			continue
+		}
+		var err error
+		if dc, ok := t.b.(backend.DeadCleaner); ok && rule.dead {
+			err = dc.RemoveDead(t.s.Name)
+		} else {
+			err = t.b.Kill(t.s.Name)
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("  failed %s: %v\n", label, err)
+		} else {
+			fmt.Printf("  pruned %s\n", label)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sessions could not be pruned", failed, len(targets))
+	}
+	return nil
+}
+
+// matches reports whether the rule prunes s, a session of the backend
+// named backendName. lastUsed gives the last time s was attached through zp.
+func (r pruneRule) matches(s backend.Session, backendName string, now time.Time, lastUsed func(backend.Session) time.Time) bool {
+	for _, g := range r.exclude {
+		if ok, _ := path.Match(g, s.Name); ok {
+			return false
+		}
+	}
+	for _, p := range r.pins {
+		if p.Matches(s, backendName) {
+			return false
+		}
+	}
+	if backend.IsProtected(r.protected, s, backendName) {
+		return false
+	}
+	if r.nameGlob != "" {
+		if ok, _ := path.Match(r.nameGlob, s.Name); !ok {
+			return false
+		}
+	}
+	if r.detached && s.Clients > 0 {
+		return false
+	}
+	if r.idleFor > 0 {
+		since := idleSince(s, lastUsed)
+		if since.IsZero() || now.Sub(since) < r.idleFor {
+			return false
+		}
+	}
+	return true
+}
+
+// idleSince returns when s was last used: its last activity when the
+// backend reports it, otherwise the later of its creation and its last
+// attach through zp. It is zero when nothing is known, and when s has
+// clients attached but no activity time, since they may be using it now.
+func idleSince(s backend.Session, lastUsed func(backend.Session) time.Time) time.Time {
+	if !s.LastActivity.IsZero() {
+		return s.LastActivity
+	}
+	if s.Clients > 0 {
+		return time.Time{}
+	}
+	since := s.CreatedAt
+	if lastUsed != nil {
+		if t := lastUsed(s); t.After(since) {
+			since = t
+		}
+	}
+	return since
+}
+
+// parsePruneArgs parses the flags of `zp prune`. Backend selection flags
+// (--all, --host, --remote) are left to loadBackend.
+func parsePruneArgs(args []string) (rule pruneRule, dryRun bool, err error) {
+	value := func(i int) (string, error) {
+		if i+1 >= len(args) {
+			return "", fmt.Errorf("%s requires a value", args[i])
+		}
+		return args[i+1], nil
+	}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--idle-for":
+			v, err := value(i)
+			if err != nil {
+				return rule, false, err
+			}
+			if rule.idleFor, err = parseAge(v); err != nil {
+				return rule, false, err
+			}
+			i++
+		case "--name-glob", "--exclude":
+			v, err := value(i)
+			if err != nil {
+				return rule, false, err
+			}
+			if _, err := path.Match(v, ""); err != nil {
+				return rule, false, fmt.Errorf("invalid pattern %q", v)
+			}
+			if args[i] == "--name-glob" {
+				rule.nameGlob = v
+			} else {
+				rule.exclude = append(rule.exclude, v)
+			}
+			i++
+		case "--detached":
+			rule.detached = true
+		case "--dead":
+			rule.dead = true
+		case "--dry-run", "-n":
+			dryRun = true
+		case "--force":
+			rule.force = true
+		case "--host":
+			i++
+		case "--all", "--remote":
+		default:
+			return rule, false, fmt.Errorf("unknown flag %q", args[i])
+		}
+	}
+	return rule, dryRun, nil
+}
+
+// parseAge parses a duration like 90m, 12h, 7d or 2w.
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v <= 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
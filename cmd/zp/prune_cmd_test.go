@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestParsePruneArgs(t *testing.T) {
+	rule, dryRun, err := parsePruneArgs([]string{"--idle-for", "7d", "--detached", "--name-glob", "scratch-*", "--exclude", "keep-*", "--all", "--dry-run"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.idleFor != 7*24*time.Hour || !rule.detached || rule.nameGlob != "scratch-*" || len(rule.exclude) != 1 || !dryRun {
+		t.Errorf("parsePruneArgs() = %+v, dryRun %v", rule, dryRun)
+	}
+
+	for _, args := range [][]string{
+		{"--idle-for"},
+		{"--idle-for", "soon"},
+		{"--idle-for", "-3d"},
+		{"--name-glob", "["},
+		{"--force-it"},
+	} {
+		if _, _, err := parsePruneArgs(args); err == nil {
+			t.Errorf("parsePruneArgs(%q) should fail", args)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"90m": 90 * time.Minute,
+		"12h": 12 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+	} {
+		if got, err := parseAge(in); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+}
+
+func TestPruneRuleMatches(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-10 * 24 * time.Hour)
+	noHistory := func(backend.Session) time.Time { return time.Time{} }
+
+	rule := pruneRule{
+		idleFor:  7 * 24 * time.Hour,
+		detached: true,
+		nameGlob: "scratch-*",
+		exclude:  []string{"scratch-keep"},
+		pins:     []backend.Pin{{Name: "scratch-pinned"}},
+
+		protected: []backend.Protected{{Name: "scratch-job", Backend: "tmux"}},
+	}
+	tests := []struct {
+		s    backend.Session
+		want bool
+	}{
+		{backend.Session{Name: "scratch-1", LastActivity: old}, true},
+		{backend.Session{Name: "scratch-2", LastActivity: now.Add(-time.Hour)}, false}, // recently used
+		{backend.Session{Name: "scratch-3", LastActivity: old, Clients: 1}, false},     // attached
+		{backend.Session{Name: "api", LastActivity: old}, false},                       // name
+		{backend.Session{Name: "scratch-keep", LastActivity: old}, false},              // excluded
+		{backend.Session{Name: "scratch-pinned", LastActivity: old}, false},            // pinned
+		{backend.Session{Name: "scratch-job", LastActivity: old}, false},               // protected
+		{backend.Session{Name: "scratch-4"}, false},                                    // age unknown
+		{backend.Session{Name: "scratch-5", CreatedAt: old}, true},                     // created long ago, never attached
+	}
+	for _, tt := range tests {
+		if got := rule.matches(tt.s, "tmux", now, noHistory); got != tt.want {
+			t.Errorf("matches(%s) = %v, want %v", tt.s.Name, got, tt.want)
+		}
+	}
+
+	// Without an activity time, attached clients may be using the session
+	// right now, however old it is.
+	idle := pruneRule{idleFor: 7 * 24 * time.Hour}
+	if idle.matches(backend.Session{Name: "work", CreatedAt: old, Clients: 1}, "zmosh", now, noHistory) {
+		t.Error("an attached session without an activity time isn't idle")
+	}
+	if !idle.matches(backend.Session{Name: "work", CreatedAt: old}, "zmosh", now, noHistory) {
+		t.Error("a detached session created long ago is idle")
+	}
+
+	// An attach through zp counts as use when the backend has no activity time.
+	attached := func(backend.Session) time.Time { return now.Add(-time.Hour) }
+	if rule.matches(backend.Session{Name: "scratch-5", CreatedAt: old}, "tmux", now, attached) {
+		t.Error("a session attached an hour ago isn't idle")
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import "github.com/nerveband/zpick/internal/backend"
+
+func runRename(oldName, newName string) error {
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	sessions, err := b.List()
+	if err != nil {
+		return err
+	}
+	if err := backend.CheckRename(sessions, oldName, newName); err != nil {
+		return err
+	}
+	if err := b.Rename(oldName, newName); err != nil {
+		return err
+	}
+	return backend.RenamePin(backend.OwnerOf(b, oldName).Name(), oldName, newName)
+}
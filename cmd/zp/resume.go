@@ -2,34 +2,45 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/history"
+	"github.com/nerveband/zpick/internal/remote"
+	"github.com/nerveband/zpick/internal/shell"
 	"github.com/nerveband/zpick/internal/switcher"
 )
 
-// runResume reads the switch-target file and outputs the shell command
-// to attach to the target session. Called by the shell hook via
-// eval "$(command zp resume)".
+// runResume takes the switch target left for this shell and outputs the
+// shell command to attach to the target session. Called by the shell hook
+// via eval "$(ZPICK_TTY=<tty> command zp resume)"; only targets keyed by
+// that tty or by no client are taken. A hook installed before targets were
+// kept per client doesn't set ZPICK_TTY and gets the legacy file instead.
 func runResume() error {
-	b, err := loadBackend(false)
-	if err != nil {
-		return err
+	var target switcher.Target
+	var err error
+	if tty, ok := os.LookupEnv(switcher.TTYEnv); ok {
+		target, err = switcher.Read(switcher.ResumeKeys(tty)...)
+	} else {
+		target, err = switcher.ReadLegacy()
 	}
-
-	target, err := switcher.Read()
 	if err != nil {
 		// No target (missing file, stale, etc.) — silent, not an error.
 		return nil
 	}
 
-	cmd := b.AttachCommand(target.Name, "")
+	b, err := resumeBackend(target.Backend)
+	if err != nil {
+		return err
+	}
 
 	switch target.Action {
 	case "attach", "new":
-		if target.Dir != "" {
-			fmt.Printf("cd %q && exec %s", target.Dir, cmd)
-		} else {
-			fmt.Printf("exec %s", cmd)
-		}
+		history.Record(target.Name, backend.OwnerOf(b, target.Name).Name())
+		cmd := backend.AttachIn(b, target.Name, target.Dir)
+		cmd.Exec = true
+		fmt.Print(cmd.Render(shell.Current()))
 	default:
 		// Unknown action — silent, not an error.
 		return nil
@@ -37,3 +48,19 @@ func runResume() error {
 
 	return nil
 }
+
+// resumeBackend returns the backend a switch target is attached through:
+// the one it names, so a switch can cross from one backend to another, or
+// the configured one for targets that don't name one. Remote backends are
+// named like "tmux@build1".
+func resumeBackend(name string) (backend.Backend, error) {
+	if name == "" {
+		return loadBackend(false)
+	}
+	if inner, host, ok := strings.Cut(name, "@"); ok {
+		h := remote.FindHost(host)
+		h.Backend = inner
+		return remote.Load(h, nil)
+	}
+	return backend.New(name)
+}
@@ -1,36 +1,44 @@
 package main
 
 import (
+	"os"
 	"os/exec"
+	"slices"
+	"strings"
 	"testing"
 )
 
 // TestResumeBuildAndSubcommand verifies that "zp resume" is a recognized
-// subcommand and exits 0 when no switch-target file exists.
+// subcommand and exits 0 with no output when no switch target is waiting,
+// whether it is run by a current hook (ZPICK_TTY set, targets under
+// ~/.cache/zpick/switch/) or by one from before per-client targets (the
+// legacy ~/.cache/zpick/switch-target file).
 func TestResumeBuildAndSubcommand(t *testing.T) {
-	// Build the binary.
-	buildCmd := exec.Command("go", "build", "-o", t.TempDir()+"/zp", "./")
+	bin := t.TempDir() + "/zp"
+	buildCmd := exec.Command("go", "build", "-o", bin, "./")
 	buildCmd.Dir = "."
 	if out, err := buildCmd.CombinedOutput(); err != nil {
 		t.Fatalf("build failed: %v\n%s", err, out)
 	}
 
-	// Run "zp resume" — should exit 0 with no output (no switch-target file).
-	bin := t.TempDir() + "/zp"
-	// Need to rebuild to the new tempdir since first one is used
-	buildCmd2 := exec.Command("go", "build", "-o", bin, "./")
-	buildCmd2.Dir = "."
-	if out, err := buildCmd2.CombinedOutput(); err != nil {
-		t.Fatalf("build failed: %v\n%s", err, out)
-	}
-
-	resumeCmd := exec.Command(bin, "resume")
-	out, err := resumeCmd.CombinedOutput()
-	if err != nil {
-		t.Fatalf("'zp resume' should exit 0 when no switch-target exists, got error: %v\n%s", err, out)
-	}
-	if len(out) > 0 {
-		t.Errorf("'zp resume' should produce no output when no switch-target exists, got: %q", out)
+	// The environment without ZPICK_TTY, as an old hook runs zp resume.
+	base := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, "ZPICK_TTY=")
+	})
+	home := t.TempDir()
+	for _, env := range [][]string{
+		{"HOME=" + home, "ZPICK_TTY=/dev/pts/3"},
+		{"HOME=" + home},
+	} {
+		resumeCmd := exec.Command(bin, "resume")
+		resumeCmd.Env = append(slices.Clone(base), env...)
+		out, err := resumeCmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("'zp resume' (%v) should exit 0 when no switch target exists, got error: %v\n%s", env, err, out)
+		}
+		if len(out) > 0 {
+			t.Errorf("'zp resume' (%v) should produce no output when no switch target exists, got: %q", env, out)
+		}
 	}
 }
 
@@ -66,3 +74,20 @@ func searchString(s, sub string) bool {
 	}
 	return false
 }
+
+func TestResumeBackend(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, name := range []string{"tmux", "zellij", "tmux@build1"} {
+		b, err := resumeBackend(name)
+		if err != nil {
+			t.Errorf("resumeBackend(%q): %v", name, err)
+			continue
+		}
+		if b.Name() != name {
+			t.Errorf("resumeBackend(%q) = %s", name, b.Name())
+		}
+	}
+	if _, err := resumeBackend("nope"); err == nil {
+		t.Error("resumeBackend(nope) should fail")
+	}
+}
@@ -0,0 +1,325 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nerveband/zpick/internal/shell"
+)
+
+// AllBackends is the backend name that selects aggregated mode, where
+// sessions from every installed backend are listed together.
+const AllBackends = "all"
+
+// Aggregate presents several backends as a single Backend.
+// Sessions returned by List and FastList are tagged with the name of the
+// backend that owns them, and per-session operations are routed back to
+// that backend.
+type Aggregate struct {
+	members []Backend
+
+	mu     sync.Mutex
+	owners map[string]string // session name -> backend name, from the last listing
+}
+
+// NewAggregate creates an aggregate over the given backends.
+func NewAggregate(members []Backend) *Aggregate {
+	return &Aggregate{members: members}
+}
+
+// LoadAll returns an aggregate of every available backend.
+func LoadAll() (Backend, error) {
+	var members []Backend
+	for _, name := range Detect() {
+		b, err := newBackend(name)
+		if err != nil {
+			continue
+		}
+		members = append(members, b)
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no supported session manager found (install zmosh, zmx, tmux, shpool, or zellij)")
+	}
+	return NewAggregate(members), nil
+}
+
+// Members returns the backends in the aggregate.
+func (a *Aggregate) Members() []Backend { return a.members }
+
+// Member returns the member backend with the given name, or nil.
+func (a *Aggregate) Member(name string) Backend {
+	for _, m := range a.members {
+		if m.Name() == name {
+			return m
+		}
+	}
+	return nil
+}
+
+func (a *Aggregate) Name() string { return AllBackends }
+
+func (a *Aggregate) BinaryName() string {
+	var names []string
+	for _, m := range a.members {
+		names = append(names, m.BinaryName())
+	}
+	return strings.Join(names, ", ")
+}
+
+func (a *Aggregate) SessionEnvVar() string {
+	if m := a.current(); m != nil {
+		return m.SessionEnvVar()
+	}
+	return ""
+}
+
+func (a *Aggregate) InSession() bool {
+	return a.current() != nil
+}
+
+func (a *Aggregate) CurrentSessionName() string {
+	if m := a.current(); m != nil {
+		return m.CurrentSessionName()
+	}
+	return ""
+}
+
+// CurrentBackend returns the name of the member we are running inside,
+// or empty if not in a session.
+func (a *Aggregate) CurrentBackend() string {
+	if m := a.current(); m != nil {
+		return m.Name()
+	}
+	return ""
+}
+
+// ClientTTY returns the client tty reported by the member we are running
+// inside, if it can tell.
+func (a *Aggregate) ClientTTY() string {
+	if cl, ok := a.current().(ClientLocator); ok {
+		return cl.ClientTTY()
+	}
+	return ""
+}
+
+func (a *Aggregate) Available() (bool, error) {
+	var errs []error
+	for _, m := range a.members {
+		ok, err := m.Available()
+		if ok {
+			return true, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return false, errors.Join(errs...)
+}
+
+// Capabilities returns the union of the members' capabilities. Per-session
+// actions should check the owning member (see Member) instead.
+func (a *Aggregate) Capabilities() Capabilities {
+	var c Capabilities
+	for _, m := range a.members {
+		c = c.Union(m.Capabilities())
+	}
+	return c
+}
+
+// Version returns the versions of all members, e.g. "tmux 3.4, zmosh 0.4.2".
+func (a *Aggregate) Version() (string, error) {
+	var parts []string
+	for _, m := range a.members {
+		if ver, err := m.Version(); err == nil {
+			parts = append(parts, m.Name()+" "+ver)
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// Versions returns the version of each member keyed by backend name.
+// Members whose version can't be determined are omitted.
+func (a *Aggregate) Versions() map[string]string {
+	versions := make(map[string]string)
+	for _, m := range a.members {
+		if ver, err := m.Version(); err == nil {
+			versions[m.Name()] = ver
+		}
+	}
+	return versions
+}
+
+func (a *Aggregate) List() ([]Session, error) {
+	return a.collect(false)
+}
+
+func (a *Aggregate) FastList() ([]Session, error) {
+	return a.collect(true)
+}
+
+// collect queries all members concurrently and merges their sessions.
+// A failing member is skipped; an error is only returned if every member fails.
+func (a *Aggregate) collect(fast bool) ([]Session, error) {
+	results := make([][]Session, len(a.members))
+	errs := make([]error, len(a.members))
+
+	var wg sync.WaitGroup
+	for i, m := range a.members {
+		wg.Go(func() {
+			var sessions []Session
+			var err error
+			if fast {
+				sessions, err = m.FastList()
+			} else {
+				sessions, err = m.List()
+			}
+			for j := range sessions {
+				sessions[j].Backend = m.Name()
+			}
+			results[i], errs[i] = sessions, err
+		})
+	}
+	wg.Wait()
+
+	var all []Session
+	failed := 0
+	owners := make(map[string]string)
+	for i, sessions := range results {
+		if errs[i] != nil {
+			failed++
+			continue
+		}
+		for _, s := range sessions {
+			if _, dup := owners[s.Name]; !dup {
+				owners[s.Name] = s.Backend
+			}
+		}
+		all = append(all, sessions...)
+	}
+	if failed > 0 && failed == len(a.members) {
+		return nil, errors.Join(errs...)
+	}
+
+	a.mu.Lock()
+	a.owners = owners
+	a.mu.Unlock()
+	return all, nil
+}
+
+func (a *Aggregate) Attach(name string) error {
+	return a.owner(name).Attach(name)
+}
+
+func (a *Aggregate) AttachCommand(name, dir string) shell.Command {
+	return AttachIn(a.owner(name), name, dir)
+}
+
+func (a *Aggregate) DetachCommand() shell.Command {
+	return a.primary().DetachCommand()
+}
+
+func (a *Aggregate) Kill(name string) error {
+	return a.owner(name).Kill(name)
+}
+
+// Rename renames the session in its owning member and updates the cached
+// owner so the new name keeps routing there.
+func (a *Aggregate) Rename(oldName, newName string) error {
+	m := a.owner(oldName)
+	if err := m.Rename(oldName, newName); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	owners := make(map[string]string, len(a.owners))
+	for name, backend := range a.owners {
+		owners[name] = backend
+	}
+	delete(owners, oldName)
+	owners[newName] = m.Name()
+	a.owners = owners
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *Aggregate) Capture(name string, lines int) (string, error) {
+	return a.owner(name).Capture(name, lines)
+}
+
+// OwnerOf returns the backend that holds the session name: the owning
+// member when b is an Aggregate (the primary one for new names), and b
+// itself otherwise.
+func OwnerOf(b Backend, name string) Backend {
+	if a, ok := b.(*Aggregate); ok {
+		return a.owner(name)
+	}
+	return b
+}
+
+// current returns the member we are running inside, or nil.
+func (a *Aggregate) current() Backend {
+	for _, m := range a.members {
+		if m.InSession() {
+			return m
+		}
+	}
+	return nil
+}
+
+// primary returns the member used for new sessions: the one we are
+// running inside, or the first member. An empty aggregate has no primary
+// member; noMembers stands in so operations fail instead of panicking.
+func (a *Aggregate) primary() Backend {
+	if m := a.current(); m != nil {
+		return m
+	}
+	if len(a.members) == 0 {
+		return noMembers{}
+	}
+	return a.members[0]
+}
+
+// owner returns the member that has a session with the given name.
+// When a name exists in several backends the first listed wins; callers
+// that know the backend should use Member instead.
+// Unknown names (new sessions) go to the primary backend.
+func (a *Aggregate) owner(name string) Backend {
+	a.mu.Lock()
+	owners := a.owners
+	a.mu.Unlock()
+	if owners == nil {
+		a.collect(true)
+		a.mu.Lock()
+		owners = a.owners
+		a.mu.Unlock()
+	}
+	if m := a.Member(owners[name]); m != nil {
+		return m
+	}
+	return a.primary()
+}
+
+// errNoMembers is returned by per-session operations on an empty aggregate.
+var errNoMembers = errors.New("no backends to run sessions in")
+
+// noMembers is the primary member of an aggregate with no members.
+// It lists nothing and fails every operation.
+type noMembers struct{}
+
+func (noMembers) Name() string                                   { return AllBackends }
+func (noMembers) BinaryName() string                             { return "" }
+func (noMembers) SessionEnvVar() string                          { return "" }
+func (noMembers) InSession() bool                                { return false }
+func (noMembers) CurrentSessionName() string                     { return "" }
+func (noMembers) Available() (bool, error)                       { return false, errNoMembers }
+func (noMembers) Version() (string, error)                       { return "", errNoMembers }
+func (noMembers) Capabilities() Capabilities                     { return Capabilities{} }
+func (noMembers) List() ([]Session, error)                       { return nil, nil }
+func (noMembers) FastList() ([]Session, error)                   { return nil, nil }
+func (noMembers) Attach(name string) error                       { return errNoMembers }
+func (noMembers) AttachCommand(name, dir string) shell.Command   { return shell.Command{} }
+func (noMembers) DetachCommand() shell.Command                   { return shell.Command{} }
+func (noMembers) Kill(name string) error                         { return errNoMembers }
+func (noMembers) Rename(oldName, newName string) error           { return errNoMembers }
+func (noMembers) Capture(name string, lines int) (string, error) { return "", errNoMembers }
@@ -0,0 +1,239 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/shell"
+)
+
+// fakeBackend is a minimal Backend for aggregate tests.
+type fakeBackend struct {
+	name      string
+	inSession bool
+	sessions  []Session
+	listErr   error
+	killed    []string
+	renamed   []string
+	caps      Capabilities
+}
+
+func (f *fakeBackend) Name() string                 { return f.name }
+func (f *fakeBackend) BinaryName() string           { return f.name }
+func (f *fakeBackend) SessionEnvVar() string        { return "FAKE_" + f.name }
+func (f *fakeBackend) InSession() bool              { return f.inSession }
+func (f *fakeBackend) CurrentSessionName() string   { return "" }
+func (f *fakeBackend) Available() (bool, error)     { return true, nil }
+func (f *fakeBackend) Version() (string, error)     { return "1.0", nil }
+func (f *fakeBackend) Capabilities() Capabilities   { return f.caps }
+func (f *fakeBackend) List() ([]Session, error)     { return f.list() }
+func (f *fakeBackend) FastList() ([]Session, error) { return f.list() }
+func (f *fakeBackend) Attach(name string) error     { return nil }
+func (f *fakeBackend) AttachCommand(name, dir string) shell.Command {
+	return shell.Command{Args: []string{f.name, "attach", name}}
+}
+func (f *fakeBackend) DetachCommand() shell.Command {
+	return shell.Command{Args: []string{f.name, "detach"}}
+}
+func (f *fakeBackend) Rename(oldName, newName string) error {
+	f.renamed = append(f.renamed, oldName+">"+newName)
+	return nil
+}
+func (f *fakeBackend) Capture(name string, lines int) (string, error) {
+	return "", Unsupported(f, "capture output")
+}
+func (f *fakeBackend) Kill(name string) error {
+	f.killed = append(f.killed, name)
+	return nil
+}
+
+func (f *fakeBackend) list() ([]Session, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	// Return a copy so tagging doesn't leak between calls.
+	return append([]Session(nil), f.sessions...), nil
+}
+
+var _ Backend = (*Aggregate)(nil)
+
+func TestAggregateListTagsSessions(t *testing.T) {
+	a := NewAggregate([]Backend{
+		&fakeBackend{name: "tmux", sessions: []Session{{Name: "work"}}},
+		&fakeBackend{name: "zmosh", sessions: []Session{{Name: "play"}, {Name: "misc"}}},
+	})
+
+	sessions, err := a.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(sessions))
+	}
+	want := map[string]string{"work": "tmux", "play": "zmosh", "misc": "zmosh"}
+	for _, s := range sessions {
+		if s.Backend != want[s.Name] {
+			t.Errorf("session %s: Backend = %q, want %q", s.Name, s.Backend, want[s.Name])
+		}
+	}
+}
+
+func TestAggregateListSkipsFailingMember(t *testing.T) {
+	a := NewAggregate([]Backend{
+		&fakeBackend{name: "tmux", listErr: errors.New("server not running")},
+		&fakeBackend{name: "zmosh", sessions: []Session{{Name: "play"}}},
+	})
+
+	sessions, err := a.FastList()
+	if err != nil {
+		t.Fatalf("expected partial results, got error: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "play" {
+		t.Errorf("expected [play], got %+v", sessions)
+	}
+}
+
+func TestAggregateListAllFail(t *testing.T) {
+	a := NewAggregate([]Backend{
+		&fakeBackend{name: "tmux", listErr: errors.New("boom")},
+		&fakeBackend{name: "zmosh", listErr: errors.New("bang")},
+	})
+	if _, err := a.List(); err == nil {
+		t.Error("expected error when every member fails")
+	}
+}
+
+func TestAggregateRoutesToOwner(t *testing.T) {
+	tmux := &fakeBackend{name: "tmux", sessions: []Session{{Name: "work"}}}
+	zmosh := &fakeBackend{name: "zmosh", sessions: []Session{{Name: "play"}}}
+	a := NewAggregate([]Backend{tmux, zmosh})
+
+	if got := a.AttachCommand("play", "").String(); got != "zmosh attach play" {
+		t.Errorf("AttachCommand(play) = %q, want zmosh attach", got)
+	}
+	if got := a.AttachCommand("brand-new", "").String(); got != "tmux attach brand-new" {
+		t.Errorf("AttachCommand(new) = %q, want primary (tmux) attach", got)
+	}
+
+	if err := a.Kill("play"); err != nil {
+		t.Fatal(err)
+	}
+	if len(zmosh.killed) != 1 || len(tmux.killed) != 0 {
+		t.Errorf("Kill routed wrong: tmux=%v zmosh=%v", tmux.killed, zmosh.killed)
+	}
+}
+
+func TestAggregateInSessionUsesCurrentMember(t *testing.T) {
+	a := NewAggregate([]Backend{
+		&fakeBackend{name: "tmux"},
+		&fakeBackend{name: "zmosh", inSession: true},
+	})
+	if !a.InSession() {
+		t.Error("expected InSession() when a member is in session")
+	}
+	if got := a.CurrentBackend(); got != "zmosh" {
+		t.Errorf("CurrentBackend() = %q, want zmosh", got)
+	}
+	if got := a.DetachCommand().String(); got != "zmosh detach" {
+		t.Errorf("DetachCommand() = %q, want zmosh detach", got)
+	}
+	// New sessions go to the backend we're in
+	if got := a.AttachCommand("fresh", "").String(); got != "zmosh attach fresh" {
+		t.Errorf("AttachCommand(fresh) = %q, want zmosh attach", got)
+	}
+}
+
+func TestAggregateMember(t *testing.T) {
+	a := NewAggregate([]Backend{&fakeBackend{name: "tmux"}})
+	if a.Member("tmux") == nil {
+		t.Error("expected tmux member")
+	}
+	if a.Member("zellij") != nil {
+		t.Error("expected nil for unknown member")
+	}
+}
+
+func TestSetBackendAll(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if err := SetBackend(AllBackends); err != nil {
+		t.Fatalf("SetBackend(all): %v", err)
+	}
+	got, _ := readBackendConfig()
+	if got != AllBackends {
+		t.Errorf("readBackendConfig() = %q, want %q", got, AllBackends)
+	}
+}
+
+func TestAggregateCapabilities(t *testing.T) {
+	tmux := &fakeBackend{name: "tmux", caps: Capabilities{Rename: true, SessionDirs: true}}
+	zmosh := &fakeBackend{name: "zmosh", caps: Capabilities{FastList: true, SessionDirs: true}}
+	c := NewAggregate([]Backend{tmux, zmosh}).Capabilities()
+	if !c.Rename || !c.FastList || !c.SessionDirs || c.PIDs {
+		t.Errorf("Capabilities() = %+v, want union of members", c)
+	}
+}
+
+func TestAttachInWithoutStartDir(t *testing.T) {
+	b := &fakeBackend{name: "zellij"}
+	cmd := AttachIn(b, "work", "/src")
+	if cmd.Dir != "/src" {
+		t.Errorf("AttachIn() should cd for backends without StartDir, got %+v", cmd)
+	}
+}
+
+// watchBackend is a fakeBackend that can attach read-only.
+type watchBackend struct{ fakeBackend }
+
+func (w *watchBackend) ReadOnlyAttachCommand(name string) shell.Command {
+	return shell.Command{Args: []string{w.name, "watch", name}}
+}
+
+func TestReadOnlyAttachCommand(t *testing.T) {
+	tmux := &watchBackend{fakeBackend{name: "tmux", caps: Capabilities{ReadOnlyAttach: true}, sessions: []Session{{Name: "api"}}}}
+	zellij := &fakeBackend{name: "zellij", sessions: []Session{{Name: "web"}}}
+	a := NewAggregate([]Backend{tmux, zellij})
+
+	if cmd, err := ReadOnlyAttachCommand(a, "api"); err != nil || cmd.String() != "tmux watch api" {
+		t.Errorf("ReadOnlyAttachCommand(api) = %q, %v; want tmux's", cmd, err)
+	}
+	if _, err := ReadOnlyAttachCommand(a, "web"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("ReadOnlyAttachCommand(web) err = %v, want ErrUnsupported", err)
+	}
+	if !a.Capabilities().ReadOnlyAttach {
+		t.Error("aggregate should offer read-only attach when a member does")
+	}
+}
+
+func TestAggregateRenameRoutesAndUpdatesOwner(t *testing.T) {
+	tmux := &fakeBackend{name: "tmux"}
+	zellij := &fakeBackend{name: "zellij", sessions: []Session{{Name: "old"}}}
+	a := NewAggregate([]Backend{tmux, zellij})
+
+	if err := a.Rename("old", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if len(zellij.renamed) != 1 || len(tmux.renamed) != 0 {
+		t.Fatalf("rename should go to zellij: tmux=%v zellij=%v", tmux.renamed, zellij.renamed)
+	}
+	if got := a.AttachCommand("new", "").String(); got != "zellij attach new" {
+		t.Errorf("renamed session should still route to zellij, got %q", got)
+	}
+}
+
+func TestEmptyAggregateFailsInsteadOfPanicking(t *testing.T) {
+	a := NewAggregate(nil)
+	if err := a.Attach("work"); err == nil {
+		t.Error("Attach() on an empty aggregate should fail")
+	}
+	if err := a.Kill("work"); err == nil {
+		t.Error("Kill() on an empty aggregate should fail")
+	}
+	if !a.DetachCommand().IsZero() {
+		t.Error("DetachCommand() on an empty aggregate should be zero")
+	}
+	if got := OwnerOf(a, "work").Name(); got != AllBackends {
+		t.Errorf("OwnerOf() = %q, want %q", got, AllBackends)
+	}
+}
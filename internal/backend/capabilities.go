@@ -0,0 +1,82 @@
+package backend
+
+import "github.com/nerveband/zpick/internal/shell"
+
+// Capabilities describes what a backend can actually do, so callers can
+// hide actions and columns it doesn't support instead of showing
+// placeholder data.
+type Capabilities struct {
+	Rename         bool `json:"rename"`           // sessions can be renamed
+	StartDir       bool `json:"start_dir"`        // AttachCommand starts new sessions in dir itself (otherwise the caller cd's first)
+	SessionDirs    bool `json:"session_dirs"`     // List reports each session's real directory
+	ClientCounts   bool `json:"client_counts"`    // List reports attached client counts
+	PIDs           bool `json:"pids"`             // List reports session PIDs
+	FastList       bool `json:"fast_list"`        // FastList is cheaper than List, but returns names and active state only
+	ReadOnlyAttach bool `json:"read_only_attach"` // clients can attach without sending input (ReadOnlyAttacher)
+	NativeSwitch   bool `json:"native_switch"`    // a client can switch sessions without detaching
+	Capture        bool `json:"capture"`          // Capture returns the session's recent screen output
+}
+
+// Names returns the supported capabilities as short labels for display.
+func (c Capabilities) Names() []string {
+	var names []string
+	for _, f := range []struct {
+		ok   bool
+		name string
+	}{
+		{c.Rename, "rename"},
+		{c.StartDir, "start dir"},
+		{c.SessionDirs, "session dirs"},
+		{c.ClientCounts, "client counts"},
+		{c.PIDs, "pids"},
+		{c.FastList, "fast list"},
+		{c.ReadOnlyAttach, "read-only attach"},
+		{c.NativeSwitch, "native switch"},
+		{c.Capture, "capture"},
+	} {
+		if f.ok {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// Union returns the capabilities supported by either c or o.
+func (c Capabilities) Union(o Capabilities) Capabilities {
+	return Capabilities{
+		Rename:         c.Rename || o.Rename,
+		StartDir:       c.StartDir || o.StartDir,
+		SessionDirs:    c.SessionDirs || o.SessionDirs,
+		ClientCounts:   c.ClientCounts || o.ClientCounts,
+		PIDs:           c.PIDs || o.PIDs,
+		FastList:       c.FastList || o.FastList,
+		ReadOnlyAttach: c.ReadOnlyAttach || o.ReadOnlyAttach,
+		NativeSwitch:   c.NativeSwitch || o.NativeSwitch,
+		Capture:        c.Capture || o.Capture,
+	}
+}
+
+// AttachIn returns b's attach command for name, started in dir. Backends
+// that can't start a session in a directory themselves get a cd instead.
+func AttachIn(b Backend, name, dir string) shell.Command {
+	if b.Capabilities().StartDir {
+		return b.AttachCommand(name, dir)
+	}
+	cmd := b.AttachCommand(name, "")
+	if dir != "" {
+		cmd.Dir = dir
+	}
+	return cmd
+}
+
+// ReadOnlyAttachCommand returns the command that attaches to the running
+// session name without sending it input, through the backend that owns
+// it. errors.ErrUnsupported if that backend can't.
+func ReadOnlyAttachCommand(b Backend, name string) (shell.Command, error) {
+	owner := OwnerOf(b, name)
+	ro, ok := owner.(ReadOnlyAttacher)
+	if !ok || !owner.Capabilities().ReadOnlyAttach {
+		return shell.Command{}, Unsupported(owner, "attach read-only")
+	}
+	return ro.ReadOnlyAttachCommand(name), nil
+}
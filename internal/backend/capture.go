@@ -0,0 +1,13 @@
+package backend
+
+import "strings"
+
+// LastLines returns the last n lines of a captured screen, ignoring the
+// blank lines below the cursor that most terminals pad the screen with.
+func LastLines(screen string, n int) string {
+	lines := strings.Split(strings.TrimRight(screen, " \t\r\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,13 @@
+package backend
+
+import "testing"
+
+func TestLastLines(t *testing.T) {
+	screen := "one\ntwo\nthree\n$ \n\n\n"
+	if got := LastLines(screen, 2); got != "three\n$" {
+		t.Errorf("LastLines(2) = %q", got)
+	}
+	if got := LastLines(screen, 10); got != "one\ntwo\nthree\n$" {
+		t.Errorf("LastLines(10) = %q", got)
+	}
+}
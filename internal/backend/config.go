@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -41,7 +42,7 @@ func readBackendConfig() (string, error) {
 // SetBackend writes the backend name to the config file.
 func SetBackend(name string) error {
 	if !isValidBackend(name) {
-		return fmt.Errorf("unknown backend %q (valid: %s)", name, strings.Join(validBackends, ", "))
+		return fmt.Errorf("unknown backend %q (valid: %s, %s)", name, strings.Join(validBackends, ", "), AllBackends)
 	}
 	dir := ConfigDir()
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -91,6 +92,7 @@ func ReadUDP() (enabled bool, host string) {
 }
 
 // Detect returns the names of all available backends (binaries found in PATH).
+// Built-in backends come first, followed by external ones in registration order.
 func Detect() []string {
 	var found []string
 	for _, name := range validBackends {
@@ -98,6 +100,11 @@ func Detect() []string {
 			found = append(found, name)
 		}
 	}
+	for _, ext := range externals {
+		if _, err := exec.LookPath(ext.binary); err == nil {
+			found = append(found, ext.name)
+		}
+	}
 	if found == nil {
 		found = []string{} // never return nil
 	}
@@ -114,6 +121,9 @@ func Load(interactive bool) (Backend, error) {
 		return nil, fmt.Errorf("reading backend config: %w", err)
 	}
 
+	if name == AllBackends {
+		return LoadAll()
+	}
 	if name != "" {
 		return newBackend(name)
 	}
@@ -148,6 +158,31 @@ func Register(name string, factory func() Backend) {
 	registry[name] = factory
 }
 
+// New creates a Backend by name from the registry.
+func New(name string) (Backend, error) {
+	return newBackend(name)
+}
+
+// external is a backend registered at runtime rather than compiled in.
+type external struct {
+	name   string
+	binary string // executable Detect looks for in PATH
+}
+
+// externals lists runtime-registered backends in registration order.
+var externals []external
+
+// RegisterExternal adds a backend that isn't built in, such as a plugin.
+// Detect reports it as available when binary is found in PATH, and
+// SetBackend accepts its name. Built-in names can't be overridden.
+func RegisterExternal(name, binary string, factory func() Backend) {
+	if isValidBackend(name) {
+		return
+	}
+	externals = append(externals, external{name: name, binary: binary})
+	registry[name] = factory
+}
+
 // newBackend creates a Backend by name from the registry.
 func newBackend(name string) (Backend, error) {
 	factory, ok := registry[name]
@@ -191,11 +226,19 @@ func promptBackend(available []string) (Backend, error) {
 }
 
 func isValidBackend(name string) bool {
+	if name == AllBackends {
+		return true
+	}
 	for _, v := range validBackends {
 		if v == name {
 			return true
 		}
 	}
+	for _, ext := range externals {
+		if ext.name == name {
+			return true
+		}
+	}
 	return false
 }
 
@@ -224,3 +267,29 @@ func SetKeyMode(mode string) error {
 	}
 	return os.WriteFile(filepath.Join(dir, "keys"), []byte(mode+"\n"), 0644)
 }
+
+// ReadSortMode returns the configured session sort mode.
+// Defaults to SortDefault if not configured or unknown.
+func ReadSortMode() string {
+	data, err := os.ReadFile(filepath.Join(ConfigDir(), "sort"))
+	if err != nil {
+		return SortDefault
+	}
+	mode := strings.TrimSpace(string(data))
+	if slices.Contains(SortModes, mode) {
+		return mode
+	}
+	return SortDefault
+}
+
+// SetSortMode writes the session sort mode to the config file.
+func SetSortMode(mode string) error {
+	if !slices.Contains(SortModes, mode) {
+		return fmt.Errorf("invalid sort mode %q (valid: %s)", mode, strings.Join(SortModes, ", "))
+	}
+	dir := ConfigDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "sort"), []byte(mode+"\n"), 0644)
+}
@@ -156,3 +156,21 @@ func TestSetKeyMode_Invalid(t *testing.T) {
 		t.Error("expected error for invalid key mode")
 	}
 }
+
+func TestSetAndReadSortMode(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmp)
+
+	if mode := ReadSortMode(); mode != SortDefault {
+		t.Errorf("expected default %q, got %q", SortDefault, mode)
+	}
+	if err := SetSortMode(SortRecent); err != nil {
+		t.Fatalf("SetSortMode: %v", err)
+	}
+	if mode := ReadSortMode(); mode != SortRecent {
+		t.Errorf("expected %q, got %q", SortRecent, mode)
+	}
+	if err := SetSortMode("size"); err == nil {
+		t.Error("expected error for invalid sort mode")
+	}
+}
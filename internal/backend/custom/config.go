@@ -0,0 +1,149 @@
+package custom
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// Parse rules for the output of a definition's list command.
+const (
+	ParseLines = "lines" // one session name per line
+	ParseKV    = "kv"    // tab-separated key=value fields, like zmosh list
+	ParseRegex = "regex" // a regex with named groups, one match per line
+)
+
+// Definition describes a backend defined in backends.conf.
+// Command templates are split on whitespace; {name} and {dir} are replaced
+// inside each word, so substituted values never become extra arguments.
+type Definition struct {
+	Name       string
+	Binary     string
+	SessionEnv string
+	List       string
+	Parse      string
+	Regex      *regexp.Regexp
+	Attach     string
+	Detach     string
+	Kill       string
+	Rename     string // {name} is the old name, {new} the new one
+	Capture    string // prints the session's screen; {lines} is how many lines are wanted
+	Version    string
+}
+
+// ConfigPath returns the path to the custom backends config file.
+func ConfigPath() string {
+	return filepath.Join(backend.ConfigDir(), "backends.conf")
+}
+
+// ReadConfig reads backends.conf. A missing file means no custom backends.
+func ReadConfig() ([]Definition, error) {
+	data, err := os.ReadFile(ConfigPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read backends config: %w", err)
+	}
+	return parseConfig(string(data))
+}
+
+// parseConfig parses INI-style sections:
+//
+//	[screen]
+//	binary = screen
+//	list = screen -ls
+//	parse = regex
+//	regex = ^\s+\d+\.(?P<name>\S+)\s+\((?P<status>\w+)\)
+//	attach = screen -xRR {name}
+func parseConfig(content string) ([]Definition, error) {
+	var defs []Definition
+	var cur *Definition
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			defs = append(defs, Definition{Name: strings.TrimSpace(line[1 : len(line)-1])})
+			cur = &defs[len(defs)-1]
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || cur == nil {
+			return nil, fmt.Errorf("backends.conf line %d: expected [name] or key = value", i+1)
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		switch k {
+		case "binary":
+			cur.Binary = v
+		case "session_env":
+			cur.SessionEnv = v
+		case "list":
+			cur.List = v
+		case "parse":
+			cur.Parse = v
+		case "regex":
+			re, err := regexp.Compile(v)
+			if err != nil {
+				return nil, fmt.Errorf("backends.conf line %d: %w", i+1, err)
+			}
+			cur.Regex = re
+		case "attach":
+			cur.Attach = v
+		case "detach":
+			cur.Detach = v
+		case "kill":
+			cur.Kill = v
+		case "rename":
+			cur.Rename = v
+		case "capture":
+			cur.Capture = v
+		case "version":
+			cur.Version = v
+		default:
+			return nil, fmt.Errorf("backends.conf line %d: unknown key %q", i+1, k)
+		}
+	}
+
+	for i := range defs {
+		if err := defs[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+	return defs, nil
+}
+
+// validate fills defaults and checks required fields.
+func (d *Definition) validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("backends.conf: backend with empty name")
+	}
+	if d.Binary == "" {
+		d.Binary = d.Name
+	}
+	if d.Parse == "" {
+		d.Parse = ParseLines
+	}
+	if d.List == "" || d.Attach == "" {
+		return fmt.Errorf("backends.conf [%s]: list and attach are required", d.Name)
+	}
+	switch d.Parse {
+	case ParseLines, ParseKV:
+	case ParseRegex:
+		if d.Regex == nil {
+			return fmt.Errorf("backends.conf [%s]: parse = regex needs a regex", d.Name)
+		}
+		if d.Regex.SubexpIndex("name") < 0 {
+			return fmt.Errorf("backends.conf [%s]: regex needs a (?P<name>...) group", d.Name)
+		}
+	default:
+		return fmt.Errorf("backends.conf [%s]: unknown parse rule %q (valid: lines, kv, regex)", d.Name, d.Parse)
+	}
+	return nil
+}
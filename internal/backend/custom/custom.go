@@ -0,0 +1,244 @@
+package custom
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/zmosh"
+	"github.com/nerveband/zpick/internal/shell"
+)
+
+// RegisterAll reads backends.conf and registers each definition as a backend.
+// Definitions can't shadow built-in backends.
+func RegisterAll() error {
+	defs, err := ReadConfig()
+	if err != nil {
+		return err
+	}
+	for _, d := range defs {
+		backend.RegisterExternal(d.Name, d.Binary, func() backend.Backend { return New(d) })
+	}
+	return nil
+}
+
+// Custom implements the Backend interface from a Definition.
+type Custom struct {
+	def Definition
+}
+
+func New(def Definition) *Custom { return &Custom{def: def} }
+
+func (c *Custom) Name() string          { return c.def.Name }
+func (c *Custom) BinaryName() string    { return c.def.Binary }
+func (c *Custom) SessionEnvVar() string { return c.def.SessionEnv }
+
+func (c *Custom) InSession() bool {
+	return c.def.SessionEnv != "" && os.Getenv(c.def.SessionEnv) != ""
+}
+
+func (c *Custom) CurrentSessionName() string {
+	if c.def.SessionEnv == "" {
+		return ""
+	}
+	return os.Getenv(c.def.SessionEnv)
+}
+
+func (c *Custom) Available() (bool, error) {
+	if _, err := exec.LookPath(c.def.Binary); err != nil {
+		return false, fmt.Errorf("%s not found in PATH", c.def.Binary)
+	}
+	return true, nil
+}
+
+// Capabilities follow from the definition: which fields the parse rule
+// can fill, and whether the attach template takes a {dir}.
+func (c *Custom) Capabilities() backend.Capabilities {
+	caps := backend.Capabilities{
+		StartDir: strings.Contains(c.def.Attach, "{dir}"),
+		Rename:   c.def.Rename != "",
+		Capture:  c.def.Capture != "",
+	}
+	switch c.def.Parse {
+	case ParseKV:
+		caps.SessionDirs, caps.ClientCounts, caps.PIDs = true, true, true
+	case ParseRegex:
+		caps.SessionDirs = c.def.Regex.SubexpIndex("dir") >= 0
+		caps.ClientCounts = c.def.Regex.SubexpIndex("clients") >= 0
+		caps.PIDs = c.def.Regex.SubexpIndex("pid") >= 0
+	}
+	return caps
+}
+
+func (c *Custom) Version() (string, error) {
+	if c.def.Version == "" {
+		return "", fmt.Errorf("%s: no version command configured", c.def.Name)
+	}
+	out, err := run(expand(c.def.Version, "", ""))
+	if err != nil {
+		return "", err
+	}
+	ver := strings.TrimSpace(out)
+	if idx := strings.IndexByte(ver, '\n'); idx >= 0 {
+		ver = strings.TrimSpace(ver[:idx])
+	}
+	return ver, nil
+}
+
+func (c *Custom) List() ([]backend.Session, error) {
+	out, err := run(c.ListArgs())
+	if err != nil && strings.TrimSpace(out) == "" {
+		// Many tools exit non-zero when there are no sessions (screen -ls does)
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to run %s: %w", c.def.List, err)
+	}
+	return c.ParseList(out), nil
+}
+
+// FastList is the same as List for custom backends.
+func (c *Custom) FastList() ([]backend.Session, error) {
+	return c.List()
+}
+
+func (c *Custom) Attach(name string) error {
+	return backend.Exec(shell.Command{Args: expand(c.def.Attach, name, "")})
+}
+
+// AttachCommand expands the attach template. When the template has no
+// {dir} placeholder the directory is applied with cd, like other backends.
+func (c *Custom) AttachCommand(name, dir string) shell.Command {
+	cmd := shell.Command{Args: expand(c.def.Attach, name, dir)}
+	if !strings.Contains(c.def.Attach, "{dir}") {
+		cmd.Dir = dir
+	}
+	return cmd
+}
+
+func (c *Custom) DetachCommand() shell.Command {
+	return shell.Command{Args: expand(c.def.Detach, "", "")}
+}
+
+func (c *Custom) Kill(name string) error {
+	if c.def.Kill == "" {
+		return fmt.Errorf("%s: no kill command configured", c.def.Name)
+	}
+	_, err := run(c.KillArgs(name))
+	return err
+}
+
+func (c *Custom) Rename(oldName, newName string) error {
+	if c.def.Rename == "" {
+		return backend.Unsupported(c, "rename sessions")
+	}
+	_, err := run(c.RenameArgs(oldName, newName))
+	return err
+}
+
+func (c *Custom) Capture(name string, lines int) (string, error) {
+	if c.def.Capture == "" {
+		return "", backend.Unsupported(c, "capture output")
+	}
+	out, err := run(c.CaptureArgs(name, lines))
+	if err != nil {
+		return "", err
+	}
+	return backend.LastLines(out, lines), nil
+}
+
+func (c *Custom) ListArgs() []string { return expand(c.def.List, "", "") }
+
+func (c *Custom) KillArgs(name string) []string { return expand(c.def.Kill, name, "") }
+
+func (c *Custom) RenameArgs(oldName, newName string) []string {
+	return expand(c.def.Rename, oldName, "", "{new}", newName)
+}
+
+func (c *Custom) CaptureArgs(name string, lines int) []string {
+	return expand(c.def.Capture, name, "", "{lines}", strconv.Itoa(lines))
+}
+
+// ParseList parses list output according to the definition's parse rule.
+func (c *Custom) ParseList(output string) []backend.Session {
+	var sessions []backend.Session
+	switch c.def.Parse {
+	case ParseKV:
+		sessions = zmosh.ParseSessions(output)
+	case ParseRegex:
+		sessions = parseRegex(c.def, output)
+	default:
+		for _, line := range strings.Split(output, "\n") {
+			if name := strings.TrimSpace(line); name != "" {
+				sessions = append(sessions, backend.Session{Name: name, StartedIn: "~"})
+			}
+		}
+	}
+	current := c.CurrentSessionName()
+	for i := range sessions {
+		if current != "" && sessions[i].Name == current {
+			sessions[i].Active = true
+		}
+	}
+	return sessions
+}
+
+// parseRegex matches each line against the definition's regex.
+// Recognized named groups: name (required), pid, clients, dir, status.
+// A status containing "attached" marks the session active.
+func parseRegex(d Definition, output string) []backend.Session {
+	var sessions []backend.Session
+	for _, line := range strings.Split(output, "\n") {
+		m := d.Regex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		s := backend.Session{StartedIn: "~"}
+		for i, group := range d.Regex.SubexpNames() {
+			switch group {
+			case "name":
+				s.Name = m[i]
+			case "pid":
+				s.PID, _ = strconv.Atoi(m[i])
+			case "clients":
+				s.Clients, _ = strconv.Atoi(m[i])
+				s.Active = s.Clients > 0
+			case "dir":
+				if m[i] != "" {
+					s.StartedIn = m[i]
+				}
+			case "status":
+				if strings.Contains(strings.ToLower(m[i]), "attached") && !strings.Contains(strings.ToLower(m[i]), "detached") {
+					s.Active = true
+				}
+			}
+		}
+		if s.Name != "" {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
+
+// expand splits a command template into words and substitutes placeholders.
+// extra holds more placeholder/value pairs, like "{new}" for rename.
+func expand(template, name, dir string, extra ...string) []string {
+	r := strings.NewReplacer(append([]string{"{name}", name, "{dir}", dir}, extra...)...)
+	words := strings.Fields(template)
+	for i, w := range words {
+		words[i] = r.Replace(w)
+	}
+	return words
+}
+
+// run executes argv and returns its stdout.
+func run(argv []string) (string, error) {
+	if len(argv) == 0 {
+		return "", fmt.Errorf("empty command")
+	}
+	out, err := exec.Command(argv[0], argv[1:]...).Output()
+	return string(out), err
+}
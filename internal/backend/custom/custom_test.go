@@ -0,0 +1,210 @@
+package custom
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+var _ backend.Backend = (*Custom)(nil)
+var _ backend.Remotable = (*Custom)(nil)
+
+const screenConf = `# GNU screen
+[screen]
+binary = screen
+session_env = STY
+list = screen -ls
+parse = regex
+regex = ^\s+(?P<pid>\d+)\.(?P<name>\S+)\s+\((?P<status>\w+)\)
+attach = screen -xRR {name}
+detach = screen -d
+kill = screen -S {name} -X quit
+rename = screen -S {name} -X sessionname {new}
+
+[dtach]
+list = ls /tmp/dtach
+attach = dtach -A /tmp/dtach/{name} -c {dir}
+`
+
+func TestParseConfig(t *testing.T) {
+	defs, err := parseConfig(screenConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 2 {
+		t.Fatalf("expected 2 definitions, got %d", len(defs))
+	}
+	if defs[0].Name != "screen" || defs[0].SessionEnv != "STY" || defs[0].Regex == nil {
+		t.Errorf("unexpected screen definition: %+v", defs[0])
+	}
+	// Defaults
+	if defs[1].Binary != "dtach" {
+		t.Errorf("binary should default to the name, got %q", defs[1].Binary)
+	}
+	if defs[1].Parse != ParseLines {
+		t.Errorf("parse should default to lines, got %q", defs[1].Parse)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing attach": "[x]\nlist = x ls\n",
+		"bad parse":      "[x]\nlist = x ls\nattach = x a\nparse = json\n",
+		"regex no name":  "[x]\nlist = x ls\nattach = x a\nparse = regex\nregex = (\\w+)\n",
+		"bad regex":      "[x]\nregex = (\n",
+		"unknown key":    "[x]\ncolour = red\n",
+		"no section":     "list = x ls\n",
+	}
+	for name, conf := range tests {
+		if _, err := parseConfig(conf); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParseRegexScreen(t *testing.T) {
+	defs, _ := parseConfig(screenConf)
+	c := New(defs[0])
+	t.Setenv("STY", "")
+
+	output := "There are screens on:\n" +
+		"\t12345.work\t(Attached)\n" +
+		"\t6789.play\t(Detached)\n" +
+		"2 Sockets in /run/screen/S-user.\n"
+	sessions := c.ParseList(output)
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].Name != "work" || sessions[0].PID != 12345 || !sessions[0].Active {
+		t.Errorf("unexpected work session: %+v", sessions[0])
+	}
+	if sessions[1].Name != "play" || sessions[1].Active {
+		t.Errorf("unexpected play session: %+v", sessions[1])
+	}
+}
+
+func TestParseLinesAndKV(t *testing.T) {
+	lines := New(Definition{Name: "x", Parse: ParseLines})
+	if got := lines.ParseList("a\n\n b \n"); len(got) != 2 || got[1].Name != "b" {
+		t.Errorf("lines parse = %+v", got)
+	}
+
+	kv := New(Definition{Name: "x", Parse: ParseKV})
+	got := kv.ParseList("session_name=api\tpid=7\tclients=1\tstarted_in=~/api\n")
+	if len(got) != 1 || got[0].Name != "api" || got[0].PID != 7 || got[0].StartedIn != "~/api" {
+		t.Errorf("kv parse = %+v", got)
+	}
+}
+
+func TestAttachCommandQuotesName(t *testing.T) {
+	defs, _ := parseConfig(screenConf)
+	c := New(defs[0])
+
+	got := c.AttachCommand("my work; rm -rf ~", "").String()
+	want := `screen -xRR 'my work; rm -rf ~'`
+	if got != want {
+		t.Errorf("AttachCommand() = %q, want %q", got, want)
+	}
+
+	got = c.AttachCommand("work", "/tmp/a b").String()
+	want = `cd '/tmp/a b' && screen -xRR work`
+	if got != want {
+		t.Errorf("AttachCommand() with dir = %q, want %q", got, want)
+	}
+}
+
+func TestAttachCommandDirPlaceholder(t *testing.T) {
+	defs, _ := parseConfig(screenConf)
+	c := New(defs[1])
+	got := c.AttachCommand("work", "/src").String()
+	want := `dtach -A /tmp/dtach/work -c /src`
+	if got != want {
+		t.Errorf("AttachCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	defs, _ := parseConfig(screenConf)
+	screen := New(defs[0]).Capabilities()
+	if !screen.PIDs || screen.ClientCounts || screen.SessionDirs || screen.StartDir {
+		t.Errorf("screen capabilities = %+v, want pids only", screen)
+	}
+	if dtach := New(defs[1]).Capabilities(); !dtach.StartDir {
+		t.Errorf("dtach should have StartDir from its {dir} placeholder: %+v", dtach)
+	}
+}
+
+func TestKillArgs(t *testing.T) {
+	defs, _ := parseConfig(screenConf)
+	got := New(defs[0]).KillArgs("work")
+	if strings.Join(got, " ") != "screen -S work -X quit" {
+		t.Errorf("KillArgs() = %q", got)
+	}
+}
+
+func TestRenameArgs(t *testing.T) {
+	defs, _ := parseConfig(screenConf)
+	got := New(defs[0]).RenameArgs("{new}", "play")
+	if strings.Join(got, " ") != "screen -S {new} -X sessionname play" {
+		t.Errorf("RenameArgs() = %q", got)
+	}
+	if err := New(defs[1]).Rename("a", "b"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Rename() without a rename command = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestCaptureArgs(t *testing.T) {
+	d := Definition{Name: "dtach", Capture: "dtach-dump -n {lines} {name}"}
+	got := New(d).CaptureArgs("work", 12)
+	if strings.Join(got, " ") != "dtach-dump -n 12 work" {
+		t.Errorf("CaptureArgs() = %q", got)
+	}
+	if !New(d).Capabilities().Capture {
+		t.Error("Capabilities().Capture = false with a capture command")
+	}
+	defs, _ := parseConfig(screenConf)
+	if _, err := New(defs[0]).Capture("a", 5); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Capture() without a capture command = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestListRunsCommand(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf 'alpha\\nbeta\\n'\n"
+	os.WriteFile(filepath.Join(dir, "fakemux"), []byte(script), 0o755)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	c := New(Definition{Name: "fakemux", Binary: "fakemux", List: "fakemux ls", Parse: ParseLines})
+	sessions, err := c.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].Name != "alpha" {
+		t.Errorf("List() = %+v", sessions)
+	}
+}
+
+func TestRegisterAll(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	os.MkdirAll(filepath.Join(dir, "zpick"), 0o755)
+	os.WriteFile(ConfigPath(), []byte(screenConf), 0o644)
+
+	if err := RegisterAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.SetBackend("screen"); err != nil {
+		t.Errorf("SetBackend should accept custom backends: %v", err)
+	}
+	b, err := backend.New("dtach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Name() != "dtach" {
+		t.Errorf("Name() = %q, want dtach", b.Name())
+	}
+}
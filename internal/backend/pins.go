@@ -0,0 +1,150 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Pin is a session the picker always lists first, on the same key,
+// whether or not it is running.
+type Pin struct {
+	Name    string
+	Backend string // backend the session lives in; empty matches any
+	Dir     string // where to recreate it; may be empty
+}
+
+// Matches reports whether s is the pinned session. Sessions without a
+// Backend (a single backend's listing) belong to defaultBackend.
+func (p Pin) Matches(s Session, defaultBackend string) bool {
+	if s.Name != p.Name {
+		return false
+	}
+	owner := s.Backend
+	if owner == "" {
+		owner = defaultBackend
+	}
+	return p.Backend == "" || p.Backend == owner
+}
+
+// NewPin returns the pin for b's session name. When b reports session
+// directories, the pin remembers where the session runs so it can be
+// recreated there.
+func NewPin(b Backend, name string) Pin {
+	p := Pin{Name: name, Backend: b.Name()}
+	if !b.Capabilities().SessionDirs {
+		return p
+	}
+	sessions, err := b.List()
+	if err != nil {
+		return p
+	}
+	for _, s := range sessions {
+		if s.Name == name {
+			p.Dir = s.StartedIn
+		}
+	}
+	return p
+}
+
+// PinsPath returns the path to the pins file.
+func PinsPath() string {
+	return filepath.Join(ConfigDir(), "pins")
+}
+
+// ReadPins returns the pinned sessions in key order. A missing file means
+// no pins. Each line is a name, optionally followed by a tab, the backend,
+// another tab and the directory.
+func ReadPins() ([]Pin, error) {
+	data, err := os.ReadFile(PinsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pins []Pin
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		p := Pin{Name: fields[0]}
+		if len(fields) > 1 {
+			p.Backend = fields[1]
+		}
+		if len(fields) > 2 {
+			p.Dir = fields[2]
+		}
+		pins = append(pins, p)
+	}
+	return pins, nil
+}
+
+// AddPin pins a session, after the existing pins. Pinning a session that
+// is already pinned updates its directory and keeps its place.
+func AddPin(p Pin) error {
+	pins, err := ReadPins()
+	if err != nil {
+		return err
+	}
+	for i, old := range pins {
+		if old.Name == p.Name && old.Backend == p.Backend {
+			pins[i] = p
+			return writePins(pins)
+		}
+	}
+	return writePins(append(pins, p))
+}
+
+// RemovePin unpins name in backendName, along with a pin of name that
+// isn't tied to a backend. An empty backendName removes the pin in every
+// backend. It reports whether anything was unpinned.
+func RemovePin(name, backendName string) (bool, error) {
+	pins, err := ReadPins()
+	if err != nil {
+		return false, err
+	}
+	var kept []Pin
+	for _, p := range pins {
+		if p.Name == name && (backendName == "" || p.Backend == "" || p.Backend == backendName) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if len(kept) == len(pins) {
+		return false, nil
+	}
+	return true, writePins(kept)
+}
+
+// RenamePin moves the pin of oldName in backendName to newName, keeping
+// its place. Sessions that aren't pinned are left alone.
+func RenamePin(backendName, oldName, newName string) error {
+	pins, err := ReadPins()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i, p := range pins {
+		if p.Name == oldName && (backendName == "" || p.Backend == "" || p.Backend == backendName) {
+			pins[i].Name = newName
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return writePins(pins)
+}
+
+func writePins(pins []Pin) error {
+	if err := os.MkdirAll(ConfigDir(), 0755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, p := range pins {
+		b.WriteString(strings.TrimRight(p.Name+"\t"+p.Backend+"\t"+p.Dir, "\t") + "\n")
+	}
+	return os.WriteFile(PinsPath(), []byte(b.String()), 0644)
+}
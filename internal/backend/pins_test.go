@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPins(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if pins, err := ReadPins(); err != nil || pins != nil {
+		t.Fatalf("ReadPins() without a file = %v, %v", pins, err)
+	}
+	AddPin(Pin{Name: "api", Backend: "tmux", Dir: "/src/api"})
+	AddPin(Pin{Name: "notes"})
+	AddPin(Pin{Name: "api", Backend: "tmux", Dir: "/srv/api"})
+
+	pins, _ := ReadPins()
+	if len(pins) != 2 || pins[0] != (Pin{Name: "api", Backend: "tmux", Dir: "/srv/api"}) || pins[1] != (Pin{Name: "notes"}) {
+		t.Fatalf("ReadPins() = %+v", pins)
+	}
+	data, _ := os.ReadFile(PinsPath())
+	if string(data) != "api\ttmux\t/srv/api\nnotes\n" {
+		t.Errorf("pins file = %q", data)
+	}
+
+	RenamePin("tmux", "api", "api2")
+	if pins, _ := ReadPins(); pins[0].Name != "api2" {
+		t.Errorf("RenamePin didn't keep the pin in place: %+v", pins)
+	}
+	RenamePin("tmux", "api2", "api")
+
+	if ok, _ := RemovePin("api", "zellij"); ok {
+		t.Error("RemovePin with another backend removed the tmux pin")
+	}
+	if ok, _ := RemovePin("api", ""); !ok {
+		t.Error("RemovePin(api) = false")
+	}
+	if pins, _ := ReadPins(); len(pins) != 1 || pins[0].Name != "notes" {
+		t.Errorf("after RemovePin: %+v", pins)
+	}
+}
+
+func TestPinMatches(t *testing.T) {
+	p := Pin{Name: "api", Backend: "tmux"}
+	if !p.Matches(Session{Name: "api"}, "tmux") {
+		t.Error("pin should match its session in a tmux listing")
+	}
+	if p.Matches(Session{Name: "api", Backend: "zellij"}, "all") {
+		t.Error("pin should not match a session of the same name in zellij")
+	}
+	if !(Pin{Name: "api"}).Matches(Session{Name: "api", Backend: "zellij"}, "all") {
+		t.Error("a pin without a backend should match any backend")
+	}
+}
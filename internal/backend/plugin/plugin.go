@@ -0,0 +1,308 @@
+// Package plugin drives external backends: executables named
+// zp-backend-<name> found in PATH.
+//
+// Each call runs the executable once, writes a single JSON request to its
+// stdin and reads a single JSON response from its stdout. See
+// docs/backend-plugins.md for the full protocol.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/shell"
+)
+
+// Prefix is the executable name prefix that marks a backend plugin.
+const Prefix = "zp-backend-"
+
+// ProtocolVersion is sent with every request so plugins can reject
+// requests they don't understand.
+const ProtocolVersion = 1
+
+// callTimeout bounds each run of a plugin, so a hung plugin can't block
+// the picker, its refreshes or the prompt hook's zp resume.
+var callTimeout = 5 * time.Second
+
+// Request is the JSON object written to the plugin's stdin.
+type Request struct {
+	Protocol int    `json:"protocol"`
+	Method   string `json:"method"`
+	Name     string `json:"name,omitempty"`
+	Dir      string `json:"dir,omitempty"`
+	NewName  string `json:"new_name,omitempty"`
+	Lines    int    `json:"lines,omitempty"`
+}
+
+// Response is the JSON object read from the plugin's stdout.
+// Only the fields relevant to the method need to be set.
+type Response struct {
+	Error string `json:"error,omitempty"`
+
+	// info
+	Binary        string                `json:"binary,omitempty"`
+	SessionEnvVar string                `json:"session_env_var,omitempty"`
+	Capabilities  *backend.Capabilities `json:"capabilities,omitempty"`
+
+	// version
+	Version string `json:"version,omitempty"`
+
+	// in_session
+	InSession      bool   `json:"in_session,omitempty"`
+	CurrentSession string `json:"current_session,omitempty"`
+
+	// list
+	Sessions []backend.Session `json:"sessions,omitempty"`
+
+	// capture
+	Output string `json:"output,omitempty"`
+
+	// attach_command, detach_command
+	Argv []string `json:"argv,omitempty"`
+	Dir  string   `json:"dir,omitempty"`
+	Env  []string `json:"env,omitempty"`
+}
+
+// command converts an attach_command or detach_command response.
+func (r Response) command() shell.Command {
+	return shell.Command{Args: r.Argv, Dir: r.Dir, Env: r.Env}
+}
+
+// Plugin implements the Backend interface by calling an external executable.
+type Plugin struct {
+	name string
+	path string // executable name or path
+
+	infoOnce sync.Once
+	info     Response
+}
+
+// New creates a plugin backend for the executable zp-backend-<name>.
+func New(name string) *Plugin {
+	return &Plugin{name: name, path: Prefix + name}
+}
+
+// Discover returns the names of all plugins found in PATH, sorted.
+// When the same plugin appears in several PATH entries the first one wins,
+// matching how exec.LookPath resolves it.
+func Discover() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), Prefix)
+			if !ok || name == "" || seen[name] || e.IsDir() {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil || info.Mode()&0o111 == 0 {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterAll discovers plugins in PATH and registers them as backends.
+// Plugins can't shadow built-in backends.
+func RegisterAll() {
+	for _, name := range Discover() {
+		backend.RegisterExternal(name, Prefix+name, func() backend.Backend { return New(name) })
+	}
+}
+
+func (p *Plugin) Name() string { return p.name }
+
+// BinaryName returns the session manager binary the plugin reports,
+// falling back to the plugin executable itself.
+func (p *Plugin) BinaryName() string {
+	if info := p.loadInfo(); info.Binary != "" {
+		return info.Binary
+	}
+	return p.path
+}
+
+func (p *Plugin) SessionEnvVar() string { return p.loadInfo().SessionEnvVar }
+
+// InSession checks the plugin's session env var when it declares one,
+// avoiding a process spawn; otherwise it asks the plugin.
+func (p *Plugin) InSession() bool {
+	if v := p.SessionEnvVar(); v != "" {
+		return os.Getenv(v) != ""
+	}
+	resp, err := p.call(Request{Method: "in_session"})
+	return err == nil && resp.InSession
+}
+
+func (p *Plugin) CurrentSessionName() string {
+	resp, err := p.call(Request{Method: "in_session"})
+	if err != nil {
+		return ""
+	}
+	return resp.CurrentSession
+}
+
+func (p *Plugin) Available() (bool, error) {
+	if _, err := exec.LookPath(p.path); err != nil {
+		return false, fmt.Errorf("%s not found in PATH", p.path)
+	}
+	if bin := p.loadInfo().Binary; bin != "" {
+		if _, err := exec.LookPath(bin); err != nil {
+			return false, fmt.Errorf("%s not found in PATH", bin)
+		}
+	}
+	return true, nil
+}
+
+// Capabilities are declared by the plugin's info response. A plugin that
+// declares none is assumed to support only the basics. The protocol has no
+// read-only attach, so that is never offered.
+func (p *Plugin) Capabilities() backend.Capabilities {
+	if c := p.loadInfo().Capabilities; c != nil {
+		caps := *c
+		caps.ReadOnlyAttach = false
+		return caps
+	}
+	return backend.Capabilities{}
+}
+
+func (p *Plugin) Version() (string, error) {
+	resp, err := p.call(Request{Method: "version"})
+	if err != nil {
+		return "", err
+	}
+	return resp.Version, nil
+}
+
+func (p *Plugin) List() ([]backend.Session, error) {
+	resp, err := p.call(Request{Method: "list"})
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Sessions {
+		if resp.Sessions[i].StartedIn == "" {
+			resp.Sessions[i].StartedIn = "~"
+		}
+	}
+	return resp.Sessions, nil
+}
+
+// FastList is the same as List for plugins.
+func (p *Plugin) FastList() ([]backend.Session, error) {
+	return p.List()
+}
+
+// Attach runs the plugin's attach command, replacing this process.
+func (p *Plugin) Attach(name string) error {
+	cmd := p.AttachCommand(name, "")
+	if cmd.IsZero() {
+		return fmt.Errorf("%s: no attach command for %q", p.path, name)
+	}
+	return backend.Exec(cmd)
+}
+
+func (p *Plugin) AttachCommand(name, dir string) shell.Command {
+	resp, err := p.call(Request{Method: "attach_command", Name: name, Dir: dir})
+	if err != nil {
+		return shell.Command{}
+	}
+	return resp.command()
+}
+
+func (p *Plugin) DetachCommand() shell.Command {
+	resp, err := p.call(Request{Method: "detach_command"})
+	if err != nil {
+		return shell.Command{}
+	}
+	return resp.command()
+}
+
+func (p *Plugin) Kill(name string) error {
+	_, err := p.call(Request{Method: "kill", Name: name})
+	return err
+}
+
+// Rename is only attempted for plugins that declare the rename capability.
+func (p *Plugin) Rename(oldName, newName string) error {
+	if !p.Capabilities().Rename {
+		return backend.Unsupported(p, "rename sessions")
+	}
+	_, err := p.call(Request{Method: "rename", Name: oldName, NewName: newName})
+	return err
+}
+
+// Capture is only attempted for plugins that declare the capture capability.
+func (p *Plugin) Capture(name string, lines int) (string, error) {
+	if !p.Capabilities().Capture {
+		return "", backend.Unsupported(p, "capture output")
+	}
+	resp, err := p.call(Request{Method: "capture", Name: name, Lines: lines})
+	if err != nil {
+		return "", err
+	}
+	return backend.LastLines(resp.Output, lines), nil
+}
+
+// loadInfo fetches and caches the plugin's identity.
+// A plugin that doesn't implement info just gets the defaults.
+func (p *Plugin) loadInfo() Response {
+	p.infoOnce.Do(func() {
+		p.info, _ = p.call(Request{Method: "info"})
+	})
+	return p.info
+}
+
+// call runs the plugin with req on stdin and decodes its response.
+func (p *Plugin) call(req Request) (Response, error) {
+	req.Protocol = ProtocolVersion
+	in, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(append(in, '\n'))
+	// Don't wait on children the killed plugin left holding its output.
+	cmd.WaitDelay = time.Second
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return Response{}, fmt.Errorf("%s %s: no response within %v", p.path, req.Method, callTimeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return Response{}, fmt.Errorf("%s %s: %s", p.path, req.Method, msg)
+		}
+		return Response{}, fmt.Errorf("%s %s: %w", p.path, req.Method, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return Response{}, fmt.Errorf("%s %s: invalid response: %w", p.path, req.Method, err)
+	}
+	if resp.Error != "" {
+		return Response{}, fmt.Errorf("%s %s: %s", p.path, req.Method, resp.Error)
+	}
+	return resp, nil
+}
@@ -0,0 +1,183 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+var _ backend.Backend = (*Plugin)(nil)
+
+// fakePlugin is a zp-backend-fake script that answers each method with
+// canned JSON and logs the requests it receives.
+const fakePlugin = `#!/bin/sh
+read -r req
+printf '%s\n' "$req" >> "$(dirname "$0")/requests"
+case "$req" in
+  *'"method":"info"'*) echo '{"binary":"sh","session_env_var":"FAKE_SESSION","capabilities":{"client_counts":true}}' ;;
+  *'"method":"version"'*) echo '{"version":"0.6"}' ;;
+  *'"method":"in_session"'*) echo '{"in_session":true,"current_session":"work"}' ;;
+  *'"method":"list"'*) echo '{"sessions":[{"name":"work","clients":1,"active":true},{"name":"play","clients":0,"started_in":"~/play"}]}' ;;
+  *'"method":"attach_command"'*) echo '{"argv":["fake","attach","my work"],"dir":"/tmp"}' ;;
+  *'"method":"detach_command"'*) echo '{"argv":["fake","detach"]}' ;;
+  *'"method":"kill"'*) echo '{"error":"session is protected"}' ;;
+  *) echo "unknown method" >&2; exit 1 ;;
+esac
+`
+
+func installFake(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, Prefix+"fake"), []byte(fakePlugin), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Non-executable and unrelated files are ignored by Discover
+	os.WriteFile(filepath.Join(dir, Prefix+"noexec"), []byte("#!/bin/sh\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "zp-other"), []byte("#!/bin/sh\n"), 0o755)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestDiscover(t *testing.T) {
+	installFake(t)
+	names := Discover()
+	if len(names) != 1 || names[0] != "fake" {
+		t.Errorf("Discover() = %v, want [fake]", names)
+	}
+}
+
+func TestPluginIdentity(t *testing.T) {
+	installFake(t)
+	p := New("fake")
+	if p.Name() != "fake" {
+		t.Errorf("Name() = %q, want fake", p.Name())
+	}
+	if p.BinaryName() != "sh" {
+		t.Errorf("BinaryName() = %q, want sh", p.BinaryName())
+	}
+	if p.SessionEnvVar() != "FAKE_SESSION" {
+		t.Errorf("SessionEnvVar() = %q, want FAKE_SESSION", p.SessionEnvVar())
+	}
+	if ok, err := p.Available(); !ok {
+		t.Errorf("Available() = false: %v", err)
+	}
+	if caps := p.Capabilities(); !caps.ClientCounts || caps.Rename {
+		t.Errorf("Capabilities() = %+v, want client counts only", caps)
+	}
+}
+
+func TestPluginInSessionUsesEnvVar(t *testing.T) {
+	installFake(t)
+	p := New("fake")
+
+	t.Setenv("FAKE_SESSION", "")
+	if p.InSession() {
+		t.Error("InSession() should be false when FAKE_SESSION is empty")
+	}
+	t.Setenv("FAKE_SESSION", "work")
+	if !p.InSession() {
+		t.Error("InSession() should be true when FAKE_SESSION is set")
+	}
+	if got := p.CurrentSessionName(); got != "work" {
+		t.Errorf("CurrentSessionName() = %q, want work", got)
+	}
+}
+
+func TestPluginList(t *testing.T) {
+	installFake(t)
+	sessions, err := New("fake").List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if !sessions[0].Active || sessions[0].Clients != 1 {
+		t.Errorf("unexpected first session: %+v", sessions[0])
+	}
+	if sessions[0].StartedIn != "~" {
+		t.Errorf("missing started_in should default to ~, got %q", sessions[0].StartedIn)
+	}
+	if sessions[1].StartedIn != "~/play" {
+		t.Errorf("StartedIn = %q, want ~/play", sessions[1].StartedIn)
+	}
+}
+
+func TestPluginCommands(t *testing.T) {
+	dir := installFake(t)
+	p := New("fake")
+
+	if got := p.AttachCommand("work", "/tmp").String(); got != `cd /tmp && fake attach 'my work'` {
+		t.Errorf("AttachCommand() = %q", got)
+	}
+	if got := p.DetachCommand().String(); got != "fake detach" {
+		t.Errorf("DetachCommand() = %q", got)
+	}
+	if ver, _ := p.Version(); ver != "0.6" {
+		t.Errorf("Version() = %q, want 0.6", ver)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "requests"))
+	want := `{"protocol":1,"method":"attach_command","name":"work","dir":"/tmp"}`
+	if !strings.Contains(string(data), want) {
+		t.Errorf("requests log missing %s:\n%s", want, data)
+	}
+}
+
+func TestPluginErrorResponse(t *testing.T) {
+	installFake(t)
+	err := New("fake").Kill("work")
+	if err == nil || !strings.Contains(err.Error(), "session is protected") {
+		t.Errorf("Kill() error = %v, want plugin error message", err)
+	}
+}
+
+func TestRegisterAll(t *testing.T) {
+	installFake(t)
+	RegisterAll()
+
+	found := false
+	for _, name := range backend.Detect() {
+		if name == "fake" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("registered plugin should be reported by Detect()")
+	}
+	b, err := backend.New("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Name() != "fake" {
+		t.Errorf("Name() = %q, want fake", b.Name())
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := backend.SetBackend("fake"); err != nil {
+		t.Errorf("SetBackend should accept plugin names: %v", err)
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, Prefix+"hung"), []byte("#!/bin/sh\nsleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	defer func(d time.Duration) { callTimeout = d }(callTimeout)
+	callTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := New("hung").List()
+	if err == nil || !strings.Contains(err.Error(), "no response") {
+		t.Errorf("List() = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("List() took %v", elapsed)
+	}
+}
@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"path/filepath"
+	"slices"
+
+	"github.com/nerveband/zpick/internal/procinfo"
+)
+
+// AddProcessInfo fills in Process for the local sessions whose PID is
+// known. It leaves sessions alone where the process table can't be read
+// (anything but Linux) and for remote sessions, whose PIDs are another
+// machine's.
+func AddProcessInfo(sessions []Session) {
+	var table *procinfo.Table
+	for i := range sessions {
+		s := &sessions[i]
+		if s.PID <= 0 || s.Host != "" {
+			continue
+		}
+		if table == nil {
+			t, err := procinfo.Snapshot()
+			if err != nil {
+				return
+			}
+			table = t
+		}
+		if info, ok := table.Inspect(s.PID); ok {
+			s.Process = &info
+		}
+	}
+}
+
+// AttachedTTY returns the tty of the client attached to the session name,
+// found in the process table as the binary's attach command for name. The
+// client is found by its own command line rather than anything the session
+// inherited, so a session created in one terminal and attached from another
+// reports the one attached now. It returns "" when that isn't exactly one
+// terminal or the process list can't be read.
+func AttachedTTY(binary, name string) string {
+	if name == "" {
+		return ""
+	}
+	tty, err := procinfo.TTYOf(func(args []string) bool { return isClientOf(args, binary, name) })
+	if err != nil {
+		return ""
+	}
+	return tty
+}
+
+// isClientOf reports whether args run a binary client for the session
+// name: "binary attach [flags] name", or "binary -s name" / "binary
+// --session name" as zellij starts one.
+func isClientOf(args []string, binary, name string) bool {
+	if len(args) < 3 || filepath.Base(args[0]) != binary {
+		return false
+	}
+	if args[1] == "attach" || args[1] == "a" {
+		return args[len(args)-1] == name
+	}
+	i := slices.IndexFunc(args, func(a string) bool { return a == "-s" || a == "--session" })
+	return i > 0 && i+1 < len(args) && args[i+1] == name
+}
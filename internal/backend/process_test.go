@@ -0,0 +1,46 @@
+package backend
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestAddProcessInfo(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process info is only read on Linux")
+	}
+	sessions := []Session{
+		{Name: "self", PID: os.Getpid()},
+		{Name: "remote", PID: os.Getpid(), Host: "build1"},
+		{Name: "unknown"},
+	}
+	AddProcessInfo(sessions)
+	if sessions[0].Process == nil || sessions[0].Process.RSS == 0 {
+		t.Errorf("self: Process = %+v, want this test's process", sessions[0].Process)
+	}
+	if sessions[1].Process != nil || sessions[2].Process != nil {
+		t.Error("remote sessions and sessions without a PID get no process info")
+	}
+}
+
+func TestIsClientOf(t *testing.T) {
+	tests := []struct {
+		binary string
+		args   []string
+		want   bool
+	}{
+		{"zmx", []string{"/usr/local/bin/zmx", "attach", "work"}, true},
+		{"zmosh", []string{"zmosh", "attach", "-r", "host", "work"}, true},
+		{"zellij", []string{"zellij", "-s", "work"}, true},
+		{"zellij", []string{"zellij", "--session", "work", "options"}, true},
+		{"zmx", []string{"zmx", "attach", "work-2"}, false},
+		{"zmx", []string{"zmosh", "attach", "work"}, false},
+		{"zmx", []string{"zmx", "kill", "work"}, false},
+	}
+	for _, tt := range tests {
+		if got := isClientOf(tt.args, tt.binary, "work"); got != tt.want {
+			t.Errorf("isClientOf(%q, %s) = %v, want %v", tt.args, tt.binary, got, tt.want)
+		}
+	}
+}
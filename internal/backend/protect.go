@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Protected is a session that bulk kills, `zp kill` and `zp prune` leave
+// alone unless forced, such as one running a long job.
+type Protected struct {
+	Name    string
+	Backend string // backend the session lives in; empty matches any
+}
+
+// Matches reports whether s is the protected session. Sessions without a
+// Backend (a single backend's listing) belong to defaultBackend.
+func (p Protected) Matches(s Session, defaultBackend string) bool {
+	return Pin{Name: p.Name, Backend: p.Backend}.Matches(s, defaultBackend)
+}
+
+// IsProtected reports whether s matches any entry of protected.
+func IsProtected(protected []Protected, s Session, defaultBackend string) bool {
+	for _, p := range protected {
+		if p.Matches(s, defaultBackend) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtectedPath returns the path to the protected sessions file.
+func ProtectedPath() string {
+	return filepath.Join(ConfigDir(), "protected")
+}
+
+// ReadProtected returns the protected sessions. A missing file means none.
+// Each line is a name, optionally followed by a tab and the backend.
+func ReadProtected() ([]Protected, error) {
+	data, err := os.ReadFile(ProtectedPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var protected []Protected
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, backendName, _ := strings.Cut(line, "\t")
+		protected = append(protected, Protected{Name: name, Backend: backendName})
+	}
+	return protected, nil
+}
+
+// Protect protects name in backendName (any backend if empty).
+func Protect(name, backendName string) error {
+	protected, err := ReadProtected()
+	if err != nil {
+		return err
+	}
+	for _, p := range protected {
+		if p.Name == name && p.Backend == backendName {
+			return nil
+		}
+	}
+	return writeProtected(append(protected, Protected{Name: name, Backend: backendName}))
+}
+
+// Unprotect removes the protection of name in backendName, along with one
+// that isn't tied to a backend. It reports whether anything was removed.
+func Unprotect(name, backendName string) (bool, error) {
+	protected, err := ReadProtected()
+	if err != nil {
+		return false, err
+	}
+	var kept []Protected
+	for _, p := range protected {
+		if p.Name == name && (backendName == "" || p.Backend == "" || p.Backend == backendName) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if len(kept) == len(protected) {
+		return false, nil
+	}
+	return true, writeProtected(kept)
+}
+
+func writeProtected(protected []Protected) error {
+	if err := os.MkdirAll(ConfigDir(), 0755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, p := range protected {
+		b.WriteString(strings.TrimRight(p.Name+"\t"+p.Backend, "\t") + "\n")
+	}
+	return os.WriteFile(ProtectedPath(), []byte(b.String()), 0644)
+}
@@ -0,0 +1,32 @@
+package backend
+
+import "testing"
+
+func TestProtect(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	Protect("train", "tmux")
+	Protect("train", "tmux")
+	Protect("notes", "")
+
+	protected, _ := ReadProtected()
+	if len(protected) != 2 {
+		t.Fatalf("ReadProtected() = %+v, want 2 entries", protected)
+	}
+	if !IsProtected(protected, Session{Name: "train"}, "tmux") {
+		t.Error("train should be protected in tmux")
+	}
+	if IsProtected(protected, Session{Name: "train", Backend: "zellij"}, "all") {
+		t.Error("train in zellij isn't protected")
+	}
+	if !IsProtected(protected, Session{Name: "notes", Backend: "zellij"}, "all") {
+		t.Error("notes is protected in every backend")
+	}
+
+	if ok, _ := Unprotect("train", "tmux"); !ok {
+		t.Error("Unprotect(train) = false")
+	}
+	if protected, _ := ReadProtected(); IsProtected(protected, Session{Name: "train"}, "tmux") {
+		t.Error("train still protected after Unprotect")
+	}
+}
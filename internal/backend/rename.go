@@ -0,0 +1,27 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckRename rejects renames of oldName to newName that would fail or
+// clobber another of the listed sessions.
+func CheckRename(sessions []Session, oldName, newName string) error {
+	if strings.TrimSpace(newName) == "" {
+		return fmt.Errorf("new name is empty")
+	}
+	found := false
+	for _, s := range sessions {
+		if s.Name == newName {
+			return fmt.Errorf("a session named %q already exists", newName)
+		}
+		if s.Name == oldName {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no session named %q", oldName)
+	}
+	return nil
+}
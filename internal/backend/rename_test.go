@@ -0,0 +1,20 @@
+package backend
+
+import "testing"
+
+func TestCheckRename(t *testing.T) {
+	sessions := []Session{{Name: "api-server-3"}, {Name: "web"}}
+
+	if err := CheckRename(sessions, "api-server-3", "api"); err != nil {
+		t.Errorf("valid rename rejected: %v", err)
+	}
+	if err := CheckRename(sessions, "api-server-3", "web"); err == nil {
+		t.Error("rename onto an existing session should fail")
+	}
+	if err := CheckRename(sessions, "missing", "x"); err == nil {
+		t.Error("rename of a missing session should fail")
+	}
+	if err := CheckRename(sessions, "web", " "); err == nil {
+		t.Error("blank new name should fail")
+	}
+}
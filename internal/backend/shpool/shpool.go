@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/shell"
 )
 
 func init() {
@@ -42,6 +43,11 @@ func (s *Shpool) Available() (bool, error) {
 	return true, nil
 }
 
+func (s *Shpool) Capabilities() backend.Capabilities {
+	// shpool list only gives names; the directory is applied with cd
+	return backend.Capabilities{}
+}
+
 func (s *Shpool) Version() (string, error) {
 	out, err := exec.Command("shpool", "version").Output()
 	if err != nil {
@@ -71,20 +77,34 @@ func (s *Shpool) Attach(name string) error {
 	return backend.ExecCommand(shpoolPath, []string{"shpool", "attach", name})
 }
 
-func (s *Shpool) DetachCommand() string { return "shpool detach" }
+func (s *Shpool) DetachCommand() shell.Command {
+	return shell.Command{Args: []string{"shpool", "detach"}}
+}
 
-func (s *Shpool) AttachCommand(name, dir string) string {
-	cmd := fmt.Sprintf(`shpool attach "%s"`, name)
-	if dir != "" {
-		return fmt.Sprintf(`cd "%s" && %s`, dir, cmd)
-	}
-	return cmd
+func (s *Shpool) AttachCommand(name, dir string) shell.Command {
+	return shell.Command{Args: []string{"shpool", "attach", name}, Dir: dir}
 }
 
 func (s *Shpool) Kill(name string) error {
 	return exec.Command("shpool", "kill", name).Run()
 }
 
+// Rename is unsupported: shpool has no rename command.
+func (s *Shpool) Rename(oldName, newName string) error {
+	return backend.Unsupported(s, "rename sessions")
+}
+
+// Capture is unsupported: shpool can't print a session's screen.
+func (s *Shpool) Capture(name string, lines int) (string, error) {
+	return "", backend.Unsupported(s, "capture output")
+}
+
+func (s *Shpool) ListArgs() []string { return []string{"shpool", "list"} }
+
+func (s *Shpool) ParseList(output string) []backend.Session { return parseShpoolSessions(output) }
+
+func (s *Shpool) KillArgs(name string) []string { return []string{"shpool", "kill", name} }
+
 // parseShpoolSessions parses the output of shpool list.
 // Each line is a session name.
 func parseShpoolSessions(output string) []backend.Session {
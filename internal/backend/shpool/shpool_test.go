@@ -1,6 +1,7 @@
 package shpool
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/nerveband/zpick/internal/backend"
@@ -47,7 +48,7 @@ func TestShpoolInSessionFalse(t *testing.T) {
 
 func TestShpoolDetachCommand(t *testing.T) {
 	b := New()
-	got := b.DetachCommand()
+	got := b.DetachCommand().String()
 	want := "shpool detach"
 	if got != want {
 		t.Errorf("DetachCommand() = %q, want %q", got, want)
@@ -56,8 +57,8 @@ func TestShpoolDetachCommand(t *testing.T) {
 
 func TestShpoolAttachCommand(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "")
-	want := `shpool attach "my-session"`
+	cmd := b.AttachCommand("my-session", "").String()
+	want := `shpool attach my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -65,8 +66,8 @@ func TestShpoolAttachCommand(t *testing.T) {
 
 func TestShpoolAttachCommandWithDir(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `cd "/tmp/foo" && shpool attach "my-session"`
+	cmd := b.AttachCommand("my-session", "/tmp/foo").String()
+	want := `cd /tmp/foo && shpool attach my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -107,3 +108,9 @@ func TestParseShpoolSessionsActive(t *testing.T) {
 		t.Error("expected play to be inactive")
 	}
 }
+
+func TestShpoolRenameUnsupported(t *testing.T) {
+	if err := New().Rename("a", "b"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Rename() = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,67 @@
+package backend
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sort modes for the picker and zp list.
+const (
+	SortDefault = "default" // the order the backend lists sessions in
+	SortRecent  = "recent"  // most recently attached through zp first
+	SortName    = "name"    // alphabetical
+	SortActive  = "active"  // sessions with clients first, then by last activity
+	SortDir     = "dir"     // grouped by start directory
+)
+
+// SortModes lists the sort modes in the order the help screen cycles them.
+var SortModes = []string{SortDefault, SortRecent, SortName, SortActive, SortDir}
+
+// SortSessions orders sessions in place by mode. lastUsed reports when zp
+// last attached to a session (zero if never) and is only used by
+// SortRecent. Ties keep the backend's order.
+func SortSessions(sessions []Session, mode string, lastUsed func(Session) time.Time) {
+	var cmpFn func(a, b Session) int
+	switch mode {
+	case SortRecent:
+		cmpFn = func(a, b Session) int { return lastUsed(b).Compare(lastUsed(a)) }
+	case SortName:
+		cmpFn = func(a, b Session) int {
+			return cmp.Or(
+				cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)),
+				cmp.Compare(a.Backend, b.Backend),
+			)
+		}
+	case SortActive:
+		cmpFn = func(a, b Session) int {
+			return cmp.Or(
+				boolFirst(a.Active, b.Active),
+				b.LastActivity.Compare(a.LastActivity),
+			)
+		}
+	case SortDir:
+		cmpFn = func(a, b Session) int {
+			return cmp.Or(
+				cmp.Compare(a.StartedIn, b.StartedIn),
+				cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)),
+			)
+		}
+	default:
+		return
+	}
+	slices.SortStableFunc(sessions, cmpFn)
+}
+
+// boolFirst orders true before false.
+func boolFirst(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	default:
+		return 1
+	}
+}
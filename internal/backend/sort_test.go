@@ -0,0 +1,45 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func sortedNames(mode string, sessions []Session, lastUsed func(Session) time.Time) string {
+	s := append([]Session(nil), sessions...)
+	SortSessions(s, mode, lastUsed)
+	var names []string
+	for _, x := range s {
+		names = append(names, x.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestSortSessions(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sessions := []Session{
+		{Name: "web", StartedIn: "/src/b"},
+		{Name: "Api", StartedIn: "/src/a", Active: true, LastActivity: t0},
+		{Name: "db", StartedIn: "/src/b", Active: true, LastActivity: t0.Add(time.Hour)},
+		{Name: "notes", StartedIn: "/src/a"},
+	}
+	used := map[string]time.Time{"notes": t0.Add(time.Hour), "web": t0}
+	lastUsed := func(s Session) time.Time { return used[s.Name] }
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{SortDefault, "web Api db notes"},
+		{SortRecent, "notes web Api db"},
+		{SortName, "Api db notes web"},
+		{SortActive, "db Api web notes"},
+		{SortDir, "Api notes db web"},
+	}
+	for _, tt := range tests {
+		if got := sortedNames(tt.mode, sessions, lastUsed); got != tt.want {
+			t.Errorf("SortSessions(%s) = %s, want %s", tt.mode, got, tt.want)
+		}
+	}
+}
@@ -6,14 +6,19 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/shell"
 )
 
 func init() {
 	backend.Register("tmux", func() backend.Backend { return New() })
 }
 
+// listFormat is the list-sessions format parsed by parseTmuxSessions.
+const listFormat = "#{session_name}\t#{session_attached}\t#{pane_current_path}\t#{session_created}\t#{session_activity}\t#{pane_pid}"
+
 // Tmux implements the Backend interface for tmux.
 type Tmux struct{}
 
@@ -35,6 +40,15 @@ func (t *Tmux) CurrentSessionName() string {
 	return strings.TrimSpace(string(out))
 }
 
+// ClientTTY returns the tty of the client attached to the current session.
+func (t *Tmux) ClientTTY() string {
+	out, err := exec.Command("tmux", "display-message", "-p", "#{client_tty}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
 func (t *Tmux) Available() (bool, error) {
 	_, err := exec.LookPath("tmux")
 	if err != nil {
@@ -43,6 +57,19 @@ func (t *Tmux) Available() (bool, error) {
 	return true, nil
 }
 
+func (t *Tmux) Capabilities() backend.Capabilities {
+	return backend.Capabilities{
+		Rename:         true,
+		StartDir:       true,
+		SessionDirs:    true,
+		ClientCounts:   true,
+		PIDs:           true,
+		ReadOnlyAttach: true,
+		NativeSwitch:   true,
+		Capture:        true,
+	}
+}
+
 func (t *Tmux) Version() (string, error) {
 	out, err := exec.Command("tmux", "-V").Output()
 	if err != nil {
@@ -57,8 +84,7 @@ func (t *Tmux) Version() (string, error) {
 }
 
 func (t *Tmux) List() ([]backend.Session, error) {
-	out, err := exec.Command("tmux", "list-sessions", "-F",
-		"#{session_name}\t#{session_attached}\t#{pane_current_path}").Output()
+	out, err := exec.Command("tmux", "list-sessions", "-F", listFormat).Output()
 	if err != nil {
 		// tmux returns error when server not running (no sessions)
 		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
@@ -82,21 +108,106 @@ func (t *Tmux) Attach(name string) error {
 	return backend.ExecCommand(tmuxPath, []string{"tmux", "new-session", "-A", "-s", name})
 }
 
-func (t *Tmux) DetachCommand() string { return "tmux detach-client" }
+func (t *Tmux) DetachCommand() shell.Command {
+	return shell.Command{Args: []string{"tmux", "detach-client"}}
+}
 
-func (t *Tmux) AttachCommand(name, dir string) string {
+func (t *Tmux) AttachCommand(name, dir string) shell.Command {
+	args := []string{"tmux", "new-session", "-A", "-s", name}
 	if dir != "" {
-		return fmt.Sprintf(`tmux new-session -A -s "%s" -c "%s"`, name, dir)
+		args = append(args, "-c", dir)
 	}
-	return fmt.Sprintf(`tmux new-session -A -s "%s"`, name)
+	return shell.Command{Args: args}
+}
+
+// ReadOnlyAttachCommand attaches with attach-session -r, whose client
+// can't type into the session or resize it.
+func (t *Tmux) ReadOnlyAttachCommand(name string) shell.Command {
+	return shell.Command{Args: []string{"tmux", "attach-session", "-r", "-t", target(name)}}
+}
+
+// SwitchTo moves the current client to name with switch-client, creating
+// the session detached first if it doesn't exist.
+func (t *Tmux) SwitchTo(name, dir string) error {
+	if exec.Command("tmux", "has-session", "-t", target(name)).Run() != nil {
+		args := []string{"tmux", "new-session", "-d", "-s", name}
+		if dir != "" {
+			args = append(args, "-c", dir)
+		}
+		if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			return fmt.Errorf("tmux new-session: %s", strings.TrimSpace(string(out)))
+		}
+	}
+	if out, err := exec.Command("tmux", "switch-client", "-t", target(name)).CombinedOutput(); err != nil {
+		return fmt.Errorf("tmux switch-client: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// PopupArgs runs argv in a display-popup that closes when it exits.
+func (t *Tmux) PopupArgs(argv []string) []string {
+	return []string{"tmux", "display-popup", "-E", "-w", "80%", "-h", "70%", shell.Join(shell.POSIX, argv)}
+}
+
+// PopupBinding binds prefix + s, tmux's own session chooser, to the popup.
+func (t *Tmux) PopupBinding(argv []string) string {
+	return "# zp: prefix + s opens the session picker in a popup. Add to ~/.tmux.conf:\n" +
+		fmt.Sprintf("bind-key s display-popup -E -w 80%% -h 70%% %q\n", shell.Join(shell.POSIX, argv))
 }
 
 func (t *Tmux) Kill(name string) error {
-	return exec.Command("tmux", "kill-session", "-t", name).Run()
+	args := t.KillArgs(name)
+	return exec.Command(args[0], args[1:]...).Run()
+}
+
+func (t *Tmux) Rename(oldName, newName string) error {
+	args := t.RenameArgs(oldName, newName)
+	if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+		return fmt.Errorf("tmux rename-session: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (t *Tmux) Capture(name string, lines int) (string, error) {
+	args := t.CaptureArgs(name, lines)
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("tmux capture-pane: %w", err)
+	}
+	return backend.LastLines(string(out), lines), nil
+}
+
+func (t *Tmux) ListArgs() []string {
+	return []string{"tmux", "list-sessions", "-F", listFormat}
+}
+
+func (t *Tmux) ParseList(output string) []backend.Session { return parseTmuxSessions(output) }
+
+func (t *Tmux) KillArgs(name string) []string {
+	return []string{"tmux", "kill-session", "-t", target(name)}
+}
+
+// CaptureArgs prints the visible screen of the session's active pane and
+// up to lines lines of scrollback above it, with wrapped lines joined. The
+// trailing colon makes tmux read the target as a session, not a pane.
+func (t *Tmux) CaptureArgs(name string, lines int) []string {
+	return []string{"tmux", "capture-pane", "-p", "-J", "-S", "-" + strconv.Itoa(lines), "-t", target(name) + ":"}
+}
+
+func (t *Tmux) RenameArgs(oldName, newName string) []string {
+	return []string{"tmux", "rename-session", "-t", target(oldName), newName}
+}
+
+// target names the session name exactly. A bare -t name also matches
+// sessions that name is a prefix or fnmatch pattern of, so killing "api"
+// with no "api" running would kill "api-server".
+func target(name string) string {
+	return "=" + name
 }
 
 // parseTmuxSessions parses the tab-separated output of tmux list-sessions.
-// Format: session_name\tsession_attached\tpane_current_path
+// Format: session_name\tsession_attached\tpane_current_path\tsession_created\tsession_activity\tpane_pid
+// The timestamps are Unix seconds; the PID is the shell of the active pane.
 func parseTmuxSessions(output string) []backend.Session {
 	var sessions []backend.Session
 	for _, line := range strings.Split(output, "\n") {
@@ -120,7 +231,23 @@ func parseTmuxSessions(output string) []backend.Session {
 		if len(fields) >= 3 {
 			s.StartedIn = fields[2]
 		}
+		if len(fields) >= 5 {
+			s.CreatedAt = parseUnix(fields[3])
+			s.LastActivity = parseUnix(fields[4])
+		}
+		if len(fields) >= 6 {
+			s.PID, _ = strconv.Atoi(fields[5])
+		}
 		sessions = append(sessions, s)
 	}
 	return sessions
 }
+
+// parseUnix converts a Unix seconds timestamp; 0 or garbage is the zero time.
+func parseUnix(v string) time.Time {
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
@@ -1,6 +1,7 @@
 package tmux
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/nerveband/zpick/internal/backend"
@@ -47,8 +48,8 @@ func TestTmuxInSessionFalse(t *testing.T) {
 
 func TestTmuxAttachCommand(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "")
-	want := `tmux new-session -A -s "my-session"`
+	cmd := b.AttachCommand("my-session", "").String()
+	want := `tmux new-session -A -s my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -56,22 +57,47 @@ func TestTmuxAttachCommand(t *testing.T) {
 
 func TestTmuxAttachCommandWithDir(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `tmux new-session -A -s "my-session" -c "/tmp/foo"`
+	cmd := b.AttachCommand("my-session", "/tmp/foo").String()
+	want := `tmux new-session -A -s my-session -c /tmp/foo`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
 }
 
+func TestTmuxReadOnlyAttachCommand(t *testing.T) {
+	b := New()
+	if !b.Capabilities().ReadOnlyAttach {
+		t.Error("Capabilities() should offer read-only attach")
+	}
+	got := b.ReadOnlyAttachCommand("api").String()
+	want := "tmux attach-session -r -t '=api'"
+	if got != want {
+		t.Errorf("ReadOnlyAttachCommand() = %q, want %q", got, want)
+	}
+}
+
 func TestTmuxDetachCommand(t *testing.T) {
 	b := New()
-	got := b.DetachCommand()
+	got := b.DetachCommand().String()
 	want := "tmux detach-client"
 	if got != want {
 		t.Errorf("DetachCommand() = %q, want %q", got, want)
 	}
 }
 
+func TestTmuxPopup(t *testing.T) {
+	b := New()
+	argv := []string{"env", "ZPICK_POPUP=1", "zp", "popup"}
+	got := strings.Join(b.PopupArgs(argv), " ")
+	want := "tmux display-popup -E -w 80% -h 70% env ZPICK_POPUP=1 zp popup"
+	if got != want {
+		t.Errorf("PopupArgs() = %q, want %q", got, want)
+	}
+	if binding := b.PopupBinding(argv); !strings.Contains(binding, `bind-key s display-popup -E -w 80% -h 70% "env ZPICK_POPUP=1 zp popup"`) {
+		t.Errorf("PopupBinding() = %q", binding)
+	}
+}
+
 func TestParseTmuxSessions(t *testing.T) {
 	output := "work\t1\t/home/user/work\nplay\t0\t/home/user/play\n"
 	sessions := parseTmuxSessions(output)
@@ -95,9 +121,46 @@ func TestParseTmuxSessions(t *testing.T) {
 	}
 }
 
+func TestParseTmuxSessionsTimestamps(t *testing.T) {
+	sessions := parseTmuxSessions("work\t0\t/src\t1771650000\t1771652000\t4242\n")
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(sessions))
+	}
+	if sessions[0].CreatedAt.Unix() != 1771650000 || sessions[0].LastActivity.Unix() != 1771652000 {
+		t.Errorf("timestamps = %v, %v", sessions[0].CreatedAt, sessions[0].LastActivity)
+	}
+	if sessions[0].PID != 4242 {
+		t.Errorf("PID = %d, want the pane's 4242", sessions[0].PID)
+	}
+}
+
 func TestParseTmuxSessionsEmpty(t *testing.T) {
 	sessions := parseTmuxSessions("")
 	if len(sessions) != 0 {
 		t.Fatalf("expected 0 sessions, got %d", len(sessions))
 	}
 }
+
+func TestTmuxRenameArgs(t *testing.T) {
+	got := strings.Join(New().RenameArgs("api-server-3", "api"), " ")
+	if got != "tmux rename-session -t =api-server-3 api" {
+		t.Errorf("RenameArgs() = %q", got)
+	}
+	if !New().Capabilities().Rename {
+		t.Error("tmux should report the rename capability")
+	}
+}
+
+func TestTmuxKillArgs(t *testing.T) {
+	got := strings.Join(New().KillArgs("api"), " ")
+	if got != "tmux kill-session -t =api" {
+		t.Errorf("KillArgs() = %q, want an exact-match target", got)
+	}
+}
+
+func TestTmuxCaptureArgs(t *testing.T) {
+	got := strings.Join(New().CaptureArgs("api", 10), " ")
+	if got != "tmux capture-pane -p -J -S -10 -t =api:" {
+		t.Errorf("CaptureArgs() = %q", got)
+	}
+}
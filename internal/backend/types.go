@@ -1,8 +1,15 @@
 package backend
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"syscall"
+	"time"
+
+	"github.com/nerveband/zpick/internal/procinfo"
+	"github.com/nerveband/zpick/internal/shell"
 )
 
 // Session represents a session from any backend.
@@ -12,6 +19,29 @@ type Session struct {
 	Clients   int    `json:"clients"`
 	StartedIn string `json:"started_in"`
 	Active    bool   `json:"active"`
+	Backend   string `json:"backend,omitempty"` // owning backend, set in aggregated mode
+	Host      string `json:"host,omitempty"`    // remote host, empty for local sessions
+
+	// Timestamps are zero when the backend doesn't report them.
+	CreatedAt    time.Time `json:"created_at,omitzero"`
+	LastActivity time.Time `json:"last_activity,omitzero"`
+
+	// TaskEndedAt is set when the session's command has exited;
+	// TaskExitCode is only meaningful then.
+	TaskEndedAt  time.Time `json:"task_ended_at,omitzero"`
+	TaskExitCode int       `json:"task_exit_code,omitempty"`
+
+	// Dead is set when the session is still listed but its server is gone,
+	// so attaching would fail (a zmx or zmosh socket left by a crash).
+	Dead bool `json:"dead,omitempty"`
+
+	// Process is what runs in the session; see AddProcessInfo.
+	Process *procinfo.Info `json:"process,omitempty"`
+}
+
+// TaskEnded reports whether the session's command has exited.
+func (s Session) TaskEnded() bool {
+	return !s.TaskEndedAt.IsZero()
 }
 
 // Backend is the interface that all session managers implement.
@@ -26,14 +56,70 @@ type Backend interface {
 	CurrentSessionName() string // name of current session (empty if not in one)
 	Available() (bool, error)
 	Version() (string, error)
+	Capabilities() Capabilities
 
 	// Runtime
 	List() ([]Session, error)
 	FastList() ([]Session, error)
 	Attach(name string) error
-	AttachCommand(name, dir string) string
-	DetachCommand() string
+	AttachCommand(name, dir string) shell.Command
+	DetachCommand() shell.Command // zero Command if the backend can't detach
 	Kill(name string) error
+	Rename(oldName, newName string) error // errors.ErrUnsupported if the backend can't
+	Capture(name string, lines int) (string, error) // last lines of the session's screen; errors.ErrUnsupported if the backend can't
+}
+
+// Remotable is implemented by backends that can be driven on another host
+// by running their CLI there (e.g. over ssh) and parsing its output.
+type Remotable interface {
+	ListArgs() []string                // argv that lists sessions
+	ParseList(output string) []Session // parses the output of ListArgs
+	KillArgs(name string) []string     // argv that kills a session
+}
+
+// DeadCleaner is implemented by backends whose sessions can outlive their
+// server, leaving entries that list but can't be attached (zmx and zmosh
+// sockets after a crash).
+type DeadCleaner interface {
+	DeadSessions() ([]Session, error) // sessions whose server no longer answers
+	RemoveDead(name string) error     // removes a dead session's leftovers
+}
+
+// ClientLocator is implemented by backends that can tell which terminal
+// the client attached to the current session runs in.
+type ClientLocator interface {
+	ClientTTY() string // the client's tty, e.g. /dev/pts/3, or "" if unknown
+}
+
+// NativeSwitcher is implemented by backends whose client can move to
+// another session in place (Capabilities.NativeSwitch), so switching from
+// inside a session needs no detach and no shell hook to resume.
+type NativeSwitcher interface {
+	// SwitchTo moves the current client to name, creating it in dir if it
+	// doesn't exist. errors.ErrUnsupported if this switch can't be done in
+	// place.
+	SwitchTo(name, dir string) error
+}
+
+// ReadOnlyAttacher is implemented by backends whose clients can watch a
+// session without sending it input (Capabilities.ReadOnlyAttach).
+type ReadOnlyAttacher interface {
+	// ReadOnlyAttachCommand returns the command that attaches to the
+	// existing session name read-only. It doesn't create the session.
+	ReadOnlyAttachCommand(name string) shell.Command
+}
+
+// Popup is implemented by backends that can run a command in a popup or
+// floating pane over the current session, for zp popup.
+type Popup interface {
+	PopupArgs(argv []string) []string  // argv that runs argv in a popup
+	PopupBinding(argv []string) string // config snippet binding a key to that popup
+}
+
+// Unsupported returns the error for an operation the backend can't perform.
+// It wraps errors.ErrUnsupported.
+func Unsupported(b Backend, op string) error {
+	return fmt.Errorf("%s can't %s: %w", b.Name(), op, errors.ErrUnsupported)
 }
 
 // AllSessionEnvVars returns env var names from all known backends.
@@ -46,3 +132,21 @@ func AllSessionEnvVars() []string {
 func ExecCommand(path string, argv []string) error {
 	return syscall.Exec(path, argv, os.Environ())
 }
+
+// Exec replaces the current process with a structured command, applying
+// its directory and environment.
+func Exec(c shell.Command) error {
+	if c.IsZero() {
+		return fmt.Errorf("empty command")
+	}
+	path, err := exec.LookPath(c.Args[0])
+	if err != nil {
+		return fmt.Errorf("%s not found: %w", c.Args[0], err)
+	}
+	if c.Dir != "" {
+		if err := os.Chdir(c.Dir); err != nil {
+			return err
+		}
+	}
+	return syscall.Exec(path, c.Args, append(os.Environ(), c.Env...))
+}
@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/shell"
 )
 
 func init() {
@@ -30,6 +34,15 @@ func (z *Zellij) CurrentSessionName() string {
 	return os.Getenv("ZELLIJ_SESSION_NAME")
 }
 
+// ClientTTY returns the tty of the client attached to the current session,
+// found from its `zellij attach` process.
+// A client that moved here with switch-session still names the session it
+// started in, so it isn't found; the switch target then falls back to the
+// default key.
+func (z *Zellij) ClientTTY() string {
+	return backend.AttachedTTY("zellij", z.CurrentSessionName())
+}
+
 func (z *Zellij) Available() (bool, error) {
 	_, err := exec.LookPath("zellij")
 	if err != nil {
@@ -38,6 +51,11 @@ func (z *Zellij) Available() (bool, error) {
 	return true, nil
 }
 
+func (z *Zellij) Capabilities() backend.Capabilities {
+	// list-sessions only gives names; the directory is applied with cd
+	return backend.Capabilities{Rename: true, NativeSwitch: true, Capture: true}
+}
+
 func (z *Zellij) Version() (string, error) {
 	out, err := exec.Command("zellij", "--version").Output()
 	if err != nil {
@@ -47,13 +65,15 @@ func (z *Zellij) Version() (string, error) {
 }
 
 func (z *Zellij) List() ([]backend.Session, error) {
-	out, err := exec.Command("zellij", "list-sessions", "--short", "--no-formatting").CombinedOutput()
+	// Without --short each line carries "[Created 2h 5m ago]", which
+	// parseSessions turns into CreatedAt.
+	out, err := exec.Command("zellij", "list-sessions", "--no-formatting").CombinedOutput()
 	if err != nil {
 		// zellij list-sessions returns exit code 1 when no sessions exist
 		if strings.Contains(string(out), "No active") || strings.TrimSpace(string(out)) == "" {
 			return nil, nil
 		}
-		// Try without --short --no-formatting flags (older zellij versions)
+		// Try without --no-formatting (older zellij versions)
 		out, err = exec.Command("zellij", "list-sessions").CombinedOutput()
 		if err != nil {
 			return nil, fmt.Errorf("failed to run zellij list-sessions: %w", err)
@@ -75,25 +95,107 @@ func (z *Zellij) Attach(name string) error {
 	return backend.ExecCommand(zellijPath, []string{"zellij", "attach", name})
 }
 
-func (z *Zellij) DetachCommand() string { return "zellij action detach" }
+func (z *Zellij) DetachCommand() shell.Command {
+	return shell.Command{Args: []string{"zellij", "action", "detach"}}
+}
+
+func (z *Zellij) AttachCommand(name, dir string) shell.Command {
+	return shell.Command{Args: []string{"zellij", "attach", name}, Dir: dir}
+}
 
-func (z *Zellij) AttachCommand(name, dir string) string {
-	cmd := fmt.Sprintf(`zellij attach "%s"`, name)
+// SwitchTo moves the current client to name with the switch-session
+// action, which also creates the session if needed. It can't choose the
+// directory of a new session, so a switch with dir is left to the
+// detach-and-resume path, as is a zellij too old to have the action.
+func (z *Zellij) SwitchTo(name, dir string) error {
 	if dir != "" {
-		return fmt.Sprintf(`cd "%s" && %s`, dir, cmd)
+		return backend.Unsupported(z, "start a session in a directory while switching")
+	}
+	if out, err := exec.Command("zellij", "action", "switch-session", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("zellij switch-session: %s", strings.TrimSpace(string(out)))
 	}
-	return cmd
+	return nil
+}
+
+// PopupArgs runs argv in a floating pane that closes when it exits.
+func (z *Zellij) PopupArgs(argv []string) []string {
+	return append([]string{"zellij", "run", "--floating", "--close-on-exit", "--name", "zp", "--"}, argv...)
+}
+
+// PopupBinding binds Alt s to the floating pane.
+func (z *Zellij) PopupBinding(argv []string) string {
+	var run strings.Builder
+	for _, arg := range argv {
+		run.WriteString(" " + strconv.Quote(arg))
+	}
+	return `// zp: Alt s opens the session picker in a floating pane. Add to the
+// keybinds block of ~/.config/zellij/config.kdl:
+shared_except "locked" {
+    bind "Alt s" {
+        Run` + run.String() + ` {
+            floating true
+            close_on_exit true
+            name "zp"
+        }
+    }
+}
+`
 }
 
 func (z *Zellij) Kill(name string) error {
 	return exec.Command("zellij", "kill-session", name).Run()
 }
 
+func (z *Zellij) Rename(oldName, newName string) error {
+	args := z.RenameArgs(oldName, newName)
+	if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+		return fmt.Errorf("zellij rename-session: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Capture dumps the focused pane of the session. The zellij server writes
+// the dump, not the CLI, so it goes through a temporary file.
+func (z *Zellij) Capture(name string, lines int) (string, error) {
+	f, err := os.CreateTemp("", "zp-dump-*")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	if out, err := exec.Command("zellij", "--session", name, "action", "dump-screen", f.Name()).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("zellij dump-screen: %s", strings.TrimSpace(string(out)))
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return backend.LastLines(string(data), lines), nil
+}
+
+func (z *Zellij) ListArgs() []string {
+	return []string{"zellij", "list-sessions", "--no-formatting"}
+}
+
+func (z *Zellij) ParseList(output string) []backend.Session { return parseSessions(output) }
+
+func (z *Zellij) KillArgs(name string) []string {
+	return []string{"zellij", "kill-session", name}
+}
+
+// RenameArgs targets the session with --session, since rename-session
+// otherwise acts on the session the command runs in.
+func (z *Zellij) RenameArgs(oldName, newName string) []string {
+	return []string{"zellij", "--session", oldName, "action", "rename-session", newName}
+}
+
 // parseSessions parses the output of zellij list-sessions.
 // Output format varies by version. With --short --no-formatting, each line is a session name.
 // Without those flags, lines may include status like "(current session)" or "EXITED".
 func parseSessions(output string) []backend.Session {
 	active := os.Getenv("ZELLIJ_SESSION_NAME")
+	now := time.Now()
 	var sessions []backend.Session
 	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
@@ -107,6 +209,7 @@ func parseSessions(output string) []backend.Session {
 
 		name := line
 		isExited := false
+		var created time.Time
 
 		// Handle status markers: "session_name (EXITED ...)" or "session_name [Created ...]"
 		if idx := strings.IndexAny(line, "(["); idx > 0 {
@@ -115,6 +218,9 @@ func parseSessions(output string) []backend.Session {
 			if strings.Contains(lower, "exited") {
 				isExited = true
 			}
+			if m := createdRe.FindStringSubmatch(line[idx:]); m != nil {
+				created = now.Add(-parseAge(m[1]))
+			}
 		}
 
 		// Skip exited/dead sessions
@@ -126,7 +232,24 @@ func parseSessions(output string) []backend.Session {
 			Name:      name,
 			StartedIn: "~",
 			Active:    name == active,
+			CreatedAt: created,
 		})
 	}
 	return sessions
 }
+
+var (
+	createdRe = regexp.MustCompile(`\[Created (.+?) ago\]`)
+	ageUnitRe = regexp.MustCompile(`(\d+)\s*(days?|h|m|s)\b`)
+)
+
+// parseAge parses zellij's session age, e.g. "1days 2h 5m 10s".
+func parseAge(s string) time.Duration {
+	units := map[string]time.Duration{"day": 24 * time.Hour, "days": 24 * time.Hour, "h": time.Hour, "m": time.Minute, "s": time.Second}
+	var d time.Duration
+	for _, m := range ageUnitRe.FindAllStringSubmatch(s, -1) {
+		n, _ := strconv.Atoi(m[1])
+		d += time.Duration(n) * units[m[2]]
+	}
+	return d
+}
@@ -1,7 +1,9 @@
 package zellij
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
 )
@@ -48,8 +50,8 @@ func TestZellijInSessionFalse(t *testing.T) {
 
 func TestZellijAttachCommand(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "")
-	want := `zellij attach "my-session"`
+	cmd := b.AttachCommand("my-session", "").String()
+	want := `zellij attach my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -57,8 +59,8 @@ func TestZellijAttachCommand(t *testing.T) {
 
 func TestZellijAttachCommandWithDir(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `cd "/tmp/foo" && zellij attach "my-session"`
+	cmd := b.AttachCommand("my-session", "/tmp/foo").String()
+	want := `cd /tmp/foo && zellij attach my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -66,7 +68,7 @@ func TestZellijAttachCommandWithDir(t *testing.T) {
 
 func TestZellijDetachCommand(t *testing.T) {
 	b := New()
-	got := b.DetachCommand()
+	got := b.DetachCommand().String()
 	want := "zellij action detach"
 	if got != want {
 		t.Errorf("DetachCommand() = %q, want %q", got, want)
@@ -131,3 +133,47 @@ func TestParseSessionsNoActive(t *testing.T) {
 		t.Fatalf("expected 0 sessions, got %d", len(sessions))
 	}
 }
+
+func TestParseSessionsCreated(t *testing.T) {
+	sessions := parseSessions("work [Created 1days 2h 5m 10s ago] (current)\nnew [Created 3s ago]\nbare\n")
+	if len(sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(sessions))
+	}
+	age := time.Since(sessions[0].CreatedAt)
+	want := 26*time.Hour + 5*time.Minute + 10*time.Second
+	if age < want || age > want+time.Minute {
+		t.Errorf("work age = %v, want about %v", age, want)
+	}
+	if time.Since(sessions[1].CreatedAt) > time.Minute {
+		t.Errorf("new should be seconds old, created %v", sessions[1].CreatedAt)
+	}
+	if !sessions[2].CreatedAt.IsZero() {
+		t.Error("a line without [Created ...] should have no CreatedAt")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	if got := parseAge("2h 3m"); got != 2*time.Hour+3*time.Minute {
+		t.Errorf("parseAge() = %v", got)
+	}
+}
+
+func TestZellijRenameArgs(t *testing.T) {
+	got := strings.Join(New().RenameArgs("api-server-3", "api"), " ")
+	if got != "zellij --session api-server-3 action rename-session api" {
+		t.Errorf("RenameArgs() = %q", got)
+	}
+}
+
+func TestZellijPopup(t *testing.T) {
+	z := New()
+	argv := []string{"env", "ZPICK_POPUP=1", "zp", "popup"}
+	got := strings.Join(z.PopupArgs(argv), " ")
+	want := "zellij run --floating --close-on-exit --name zp -- env ZPICK_POPUP=1 zp popup"
+	if got != want {
+		t.Errorf("PopupArgs() = %q, want %q", got, want)
+	}
+	if binding := z.PopupBinding(argv); !strings.Contains(binding, `Run "env" "ZPICK_POPUP=1" "zp" "popup" {`) {
+		t.Errorf("PopupBinding() = %q", binding)
+	}
+}
@@ -3,12 +3,14 @@ package zmosh
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
 )
 
 // ParseSessions parses the tab-separated output of `zmosh list`.
 // Each line has fields like: session_name=foo\tpid=123\tclients=1\tstarted_in=~/bar
+// Timestamps (created_at, task_ended_at) are Unix nanoseconds, 0 when unset.
 // Lines may have leading whitespace or a → prefix for the current session.
 func ParseSessions(output string) []backend.Session {
 	var sessions []backend.Session
@@ -35,6 +37,12 @@ func ParseSessions(output string) []backend.Session {
 					s.Clients, _ = strconv.Atoi(v)
 				case "started_in":
 					s.StartedIn = v
+				case "created_at":
+					s.CreatedAt = parseNanos(v)
+				case "task_ended_at":
+					s.TaskEndedAt = parseNanos(v)
+				case "task_exit_code":
+					s.TaskExitCode, _ = strconv.Atoi(v)
 				}
 			}
 		}
@@ -48,3 +56,12 @@ func ParseSessions(output string) []backend.Session {
 
 	return sessions
 }
+
+// parseNanos converts a Unix nanosecond timestamp; 0 or garbage is the zero time.
+func parseNanos(v string) time.Time {
+	ns, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || ns <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
@@ -0,0 +1,22 @@
+package zmosh
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// peerPID returns the PID of the process at the other end of conn.
+func peerPID(conn *net.UnixConn) int {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0
+	}
+	pid := 0
+	raw.Control(func(fd uintptr) {
+		if n, err := unix.GetsockoptInt(int(fd), unix.SOL_LOCAL, unix.LOCAL_PEERPID); err == nil {
+			pid = n
+		}
+	})
+	return pid
+}
@@ -0,0 +1,22 @@
+package zmosh
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// peerPID returns the PID of the process at the other end of conn.
+func peerPID(conn *net.UnixConn) int {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0
+	}
+	pid := 0
+	raw.Control(func(fd uintptr) {
+		if cred, err := unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED); err == nil {
+			pid = int(cred.Pid)
+		}
+	})
+	return pid
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package zmosh
+
+import "net"
+
+// peerPID is only implemented on Linux and macOS.
+func peerPID(conn *net.UnixConn) int { return 0 }
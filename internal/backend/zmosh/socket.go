@@ -1,12 +1,17 @@
 package zmosh
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
 )
@@ -48,7 +53,12 @@ func ResolveZmxDir() (string, error) {
 	return "", fmt.Errorf("could not resolve zmx socket directory")
 }
 
-// FastListDir reads session names directly from socket files in the zmx directory.
+// FastListDir reads session names directly from socket files in the zmx
+// directory. Each socket is probed, in parallel: sessions whose server no
+// longer answers are marked Dead, and the others get the server's PID from
+// the socket's peer credentials where the OS reports them. Client counts
+// stay 0: the sockets only speak the attach protocol, which has no way to
+// ask for them.
 func FastListDir(dir string) ([]backend.Session, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -67,15 +77,67 @@ func FastListDir(dir string) ([]backend.Session, error) {
 		if info.Mode().Type()&os.ModeSocket == 0 {
 			continue
 		}
+		// The socket is created with the session and never rewritten,
+		// so its mtime is the session's creation time.
 		sessions = append(sessions, backend.Session{
 			Name:      e.Name(),
 			StartedIn: "~",
 			Active:    e.Name() == active,
+			CreatedAt: info.ModTime(),
 		})
 	}
+
+	var wg sync.WaitGroup
+	for i := range sessions {
+		wg.Go(func() {
+			sessions[i].Dead, sessions[i].PID = probeSocket(filepath.Join(dir, sessions[i].Name))
+		})
+	}
+	wg.Wait()
 	return sessions, nil
 }
 
+// probeTimeout bounds how long probing a session's socket may take.
+const probeTimeout = 200 * time.Millisecond
+
+// DeadSessionsDir returns the sessions in dir whose socket no server is
+// listening on any more, as left behind when a session's server crashes.
+func DeadSessionsDir(dir string) ([]backend.Session, error) {
+	sessions, err := FastListDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var dead []backend.Session
+	for _, s := range sessions {
+		if s.Dead {
+			dead = append(dead, s)
+		}
+	}
+	return dead, nil
+}
+
+// RemoveDeadSocket removes the socket of a dead session in dir. It refuses
+// if a server answers on the socket after all.
+func RemoveDeadSocket(dir, name string) error {
+	sock := filepath.Join(dir, name)
+	if dead, _ := probeSocket(sock); !dead {
+		return fmt.Errorf("session %q is still running", name)
+	}
+	return os.Remove(sock)
+}
+
+// probeSocket connects to the socket at path. dead reports whether the
+// connection is refused; a server that is merely slow to accept isn't
+// dead. pid is the server's, or 0 if it isn't known.
+func probeSocket(path string) (dead bool, pid int) {
+	conn, err := net.DialTimeout("unix", path, probeTimeout)
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED), 0
+	}
+	defer conn.Close()
+	return false, peerPID(conn.(*net.UnixConn))
+}
+
 // itoa is a helper for strconv.Itoa.
 func itoa(i int) string {
 	return strconv.Itoa(i)
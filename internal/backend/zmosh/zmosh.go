@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/shell"
 )
 
 func init() {
@@ -35,6 +36,12 @@ func (z *Zmosh) CurrentSessionName() string {
 	return os.Getenv("ZMX_SESSION")
 }
 
+// ClientTTY returns the tty of the client attached to the current session,
+// found from its `zmosh attach` process.
+func (z *Zmosh) ClientTTY() string {
+	return backend.AttachedTTY("zmosh", z.CurrentSessionName())
+}
+
 func (z *Zmosh) Available() (bool, error) {
 	_, err := exec.LookPath("zmosh")
 	if err != nil {
@@ -43,6 +50,15 @@ func (z *Zmosh) Available() (bool, error) {
 	return true, nil
 }
 
+func (z *Zmosh) Capabilities() backend.Capabilities {
+	return backend.Capabilities{
+		SessionDirs:  true,
+		ClientCounts: true,
+		PIDs:         true,
+		FastList:     true,
+	}
+}
+
 func (z *Zmosh) Version() (string, error) {
 	out, err := exec.Command("zmosh", "version").Output()
 	if err != nil {
@@ -87,26 +103,25 @@ func (z *Zmosh) Attach(name string) error {
 	return backend.ExecCommand(zmoshPath, []string{"zmosh", "attach", name})
 }
 
-func (z *Zmosh) DetachCommand() string { return "zmx detach" }
+func (z *Zmosh) DetachCommand() shell.Command {
+	return shell.Command{Args: []string{"zmx", "detach"}}
+}
 
-func (z *Zmosh) AttachCommand(name, dir string) string {
+func (z *Zmosh) AttachCommand(name, dir string) shell.Command {
 	// Check UDP config for -r flag
-	enabled, host := backend.ReadUDP()
-	var attachCmd string
-	if enabled && host != "" {
-		attachCmd = fmt.Sprintf(`zmosh attach -r %s "%s"`, host, name)
-	} else {
-		attachCmd = fmt.Sprintf(`zmosh attach "%s"`, name)
-	}
-
-	if dir != "" {
-		return fmt.Sprintf(`cd "%s" && %s`, dir, attachCmd)
+	args := []string{"zmosh", "attach"}
+	if enabled, host := backend.ReadUDP(); enabled && host != "" {
+		args = append(args, "-r", host)
 	}
-	return attachCmd
+	return shell.Command{Args: append(args, name), Dir: dir}
 }
 
 func (z *Zmosh) Kill(name string) error {
 	if err := exec.Command("zmosh", "kill", name).Run(); err != nil {
+		// A session whose server crashed can't be killed, only cleaned up.
+		if dir, dirErr := ResolveZmxDir(); dirErr == nil && RemoveDeadSocket(dir, name) == nil {
+			return nil
+		}
 		return err
 	}
 	// FastList reads socket files directly from the zmx directory.
@@ -126,3 +141,37 @@ func (z *Zmosh) Kill(name string) error {
 	os.Remove(sock)
 	return nil
 }
+
+// DeadSessions returns the sessions whose socket outlived their server.
+func (z *Zmosh) DeadSessions() ([]backend.Session, error) {
+	dir, err := ResolveZmxDir()
+	if err != nil {
+		return nil, err
+	}
+	return DeadSessionsDir(dir)
+}
+
+// RemoveDead removes a dead session's socket.
+func (z *Zmosh) RemoveDead(name string) error {
+	dir, err := ResolveZmxDir()
+	if err != nil {
+		return err
+	}
+	return RemoveDeadSocket(dir, name)
+}
+
+// Rename is unsupported: zmosh has no rename command.
+func (z *Zmosh) Rename(oldName, newName string) error {
+	return backend.Unsupported(z, "rename sessions")
+}
+
+// Capture is unsupported: zmosh can't print a session's screen.
+func (z *Zmosh) Capture(name string, lines int) (string, error) {
+	return "", backend.Unsupported(z, "capture output")
+}
+
+func (z *Zmosh) ListArgs() []string { return []string{"zmosh", "list"} }
+
+func (z *Zmosh) ParseList(output string) []backend.Session { return ParseSessions(output) }
+
+func (z *Zmosh) KillArgs(name string) []string { return []string{"zmosh", "kill", name} }
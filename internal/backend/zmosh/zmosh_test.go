@@ -4,6 +4,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/nerveband/zpick/internal/backend"
@@ -51,8 +52,8 @@ func TestZmoshInSessionFalse(t *testing.T) {
 
 func TestZmoshAttachCommand(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "")
-	want := `zmosh attach "my-session"`
+	cmd := b.AttachCommand("my-session", "").String()
+	want := `zmosh attach my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -60,8 +61,8 @@ func TestZmoshAttachCommand(t *testing.T) {
 
 func TestZmoshAttachCommandWithDir(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `cd "/tmp/foo" && zmosh attach "my-session"`
+	cmd := b.AttachCommand("my-session", "/tmp/foo").String()
+	want := `cd /tmp/foo && zmosh attach my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -73,8 +74,8 @@ func TestZmoshAttachCommandUDP(t *testing.T) {
 	backend.SetUDP(true, "myhost")
 
 	b := New()
-	cmd := b.AttachCommand("my-session", "")
-	want := `zmosh attach -r myhost "my-session"`
+	cmd := b.AttachCommand("my-session", "").String()
+	want := `zmosh attach -r myhost my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -86,9 +87,9 @@ func TestZmoshAttachCommandUDPNoHost(t *testing.T) {
 	backend.SetUDP(true, "")
 
 	b := New()
-	cmd := b.AttachCommand("my-session", "")
+	cmd := b.AttachCommand("my-session", "").String()
 	// No host set — no -r flag
-	want := `zmosh attach "my-session"`
+	want := `zmosh attach my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -96,7 +97,7 @@ func TestZmoshAttachCommandUDPNoHost(t *testing.T) {
 
 func TestZmoshDetachCommand(t *testing.T) {
 	b := New()
-	got := b.DetachCommand()
+	got := b.DetachCommand().String()
 	want := "zmx detach"
 	if got != want {
 		t.Errorf("DetachCommand() = %q, want %q", got, want)
@@ -123,6 +124,24 @@ func TestParseSessions(t *testing.T) {
 	}
 }
 
+func TestParseSessionsTimestamps(t *testing.T) {
+	input := "session_name=build\tpid=1\tclients=0\tcreated_at=1771652262707138000\ttask_ended_at=1771652300000000000\ttask_exit_code=3\tstarted_in=~\n" +
+		"session_name=shell\tpid=2\tclients=0\tcreated_at=1771652262707138000\ttask_ended_at=0\ttask_exit_code=0\tstarted_in=~\n"
+	sessions := ParseSessions(input)
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if got := sessions[0].CreatedAt.UnixNano(); got != 1771652262707138000 {
+		t.Errorf("CreatedAt = %d", got)
+	}
+	if !sessions[0].TaskEnded() || sessions[0].TaskExitCode != 3 {
+		t.Errorf("build task should have ended with 3: %+v", sessions[0])
+	}
+	if sessions[1].TaskEnded() {
+		t.Error("task_ended_at=0 means the task is still running")
+	}
+}
+
 func TestParseEmpty(t *testing.T) {
 	sessions := ParseSessions("")
 	if len(sessions) != 0 {
@@ -172,6 +191,14 @@ func TestFastListDir(t *testing.T) {
 			t.Errorf("expected StartedIn=~, got %q", s.StartedIn)
 		}
 	}
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		// This test is the server at the other end of the sockets.
+		for _, s := range sessions {
+			if s.PID != os.Getpid() {
+				t.Errorf("%s: PID = %d, want the listener's %d", s.Name, s.PID, os.Getpid())
+			}
+		}
+	}
 	if !names["work"] || !names["play"] {
 		t.Errorf("expected work and play sessions, got %v", names)
 	}
@@ -233,3 +260,49 @@ func TestResolveZmxDirTMPDIR(t *testing.T) {
 		t.Errorf("expected %q, got %q", candidate, got)
 	}
 }
+
+func TestDeadSessionsDir(t *testing.T) {
+	dir := t.TempDir()
+
+	live, err := net.Listen("unix", filepath.Join(dir, "live"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer live.Close()
+
+	// A server that exits without unlinking its socket leaves it behind.
+	crashed, err := net.Listen("unix", filepath.Join(dir, "crashed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crashed.(*net.UnixListener).SetUnlinkOnClose(false)
+	crashed.Close()
+
+	sessions, err := FastListDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sessions {
+		if s.Dead != (s.Name == "crashed") {
+			t.Errorf("FastListDir: %s Dead = %v", s.Name, s.Dead)
+		}
+	}
+
+	dead, err := DeadSessionsDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || dead[0].Name != "crashed" {
+		t.Fatalf("DeadSessionsDir() = %+v, want only crashed", dead)
+	}
+
+	if err := RemoveDeadSocket(dir, "live"); err == nil {
+		t.Error("RemoveDeadSocket removed a live session's socket")
+	}
+	if err := RemoveDeadSocket(dir, "crashed"); err != nil {
+		t.Fatalf("RemoveDeadSocket(crashed) = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "crashed")); !os.IsNotExist(err) {
+		t.Error("dead socket still exists")
+	}
+}
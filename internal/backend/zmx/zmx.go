@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/shell"
 	zmoshpkg "github.com/nerveband/zpick/internal/backend/zmosh"
 )
 
@@ -33,6 +34,12 @@ func (z *Zmx) CurrentSessionName() string {
 	return os.Getenv("ZMX_SESSION")
 }
 
+// ClientTTY returns the tty of the client attached to the current session,
+// found from its `zmx attach` process.
+func (z *Zmx) ClientTTY() string {
+	return backend.AttachedTTY("zmx", z.CurrentSessionName())
+}
+
 func (z *Zmx) Available() (bool, error) {
 	_, err := exec.LookPath("zmx")
 	if err != nil {
@@ -41,6 +48,15 @@ func (z *Zmx) Available() (bool, error) {
 	return true, nil
 }
 
+func (z *Zmx) Capabilities() backend.Capabilities {
+	return backend.Capabilities{
+		SessionDirs:  true,
+		ClientCounts: true,
+		PIDs:         true,
+		FastList:     true,
+	}
+}
+
 func (z *Zmx) Version() (string, error) {
 	out, err := exec.Command("zmx", "version").Output()
 	if err != nil {
@@ -86,18 +102,20 @@ func (z *Zmx) Attach(name string) error {
 	return backend.ExecCommand(zmxPath, []string{"zmx", "attach", name})
 }
 
-func (z *Zmx) DetachCommand() string { return "zmx detach" }
+func (z *Zmx) DetachCommand() shell.Command {
+	return shell.Command{Args: []string{"zmx", "detach"}}
+}
 
-func (z *Zmx) AttachCommand(name, dir string) string {
-	cmd := fmt.Sprintf(`zmx attach "%s"`, name)
-	if dir != "" {
-		return fmt.Sprintf(`cd "%s" && %s`, dir, cmd)
-	}
-	return cmd
+func (z *Zmx) AttachCommand(name, dir string) shell.Command {
+	return shell.Command{Args: []string{"zmx", "attach", name}, Dir: dir}
 }
 
 func (z *Zmx) Kill(name string) error {
 	if err := exec.Command("zmx", "kill", name).Run(); err != nil {
+		// A session whose server crashed can't be killed, only cleaned up.
+		if dir, dirErr := zmoshpkg.ResolveZmxDir(); dirErr == nil && zmoshpkg.RemoveDeadSocket(dir, name) == nil {
+			return nil
+		}
 		return err
 	}
 	dir, err := zmoshpkg.ResolveZmxDir()
@@ -114,3 +132,37 @@ func (z *Zmx) Kill(name string) error {
 	os.Remove(sock)
 	return nil
 }
+
+// DeadSessions returns the sessions whose socket outlived their server.
+func (z *Zmx) DeadSessions() ([]backend.Session, error) {
+	dir, err := zmoshpkg.ResolveZmxDir()
+	if err != nil {
+		return nil, err
+	}
+	return zmoshpkg.DeadSessionsDir(dir)
+}
+
+// RemoveDead removes a dead session's socket.
+func (z *Zmx) RemoveDead(name string) error {
+	dir, err := zmoshpkg.ResolveZmxDir()
+	if err != nil {
+		return err
+	}
+	return zmoshpkg.RemoveDeadSocket(dir, name)
+}
+
+// Rename is unsupported: zmx has no rename command.
+func (z *Zmx) Rename(oldName, newName string) error {
+	return backend.Unsupported(z, "rename sessions")
+}
+
+// Capture is unsupported: zmx can't print a session's screen.
+func (z *Zmx) Capture(name string, lines int) (string, error) {
+	return "", backend.Unsupported(z, "capture output")
+}
+
+func (z *Zmx) ListArgs() []string { return []string{"zmx", "list"} }
+
+func (z *Zmx) ParseList(output string) []backend.Session { return zmoshpkg.ParseSessions(output) }
+
+func (z *Zmx) KillArgs(name string) []string { return []string{"zmx", "kill", name} }
@@ -39,7 +39,7 @@ func TestZmxInSession(t *testing.T) {
 
 func TestZmxDetachCommand(t *testing.T) {
 	b := New()
-	got := b.DetachCommand()
+	got := b.DetachCommand().String()
 	want := "zmx detach"
 	if got != want {
 		t.Errorf("DetachCommand() = %q, want %q", got, want)
@@ -48,8 +48,8 @@ func TestZmxDetachCommand(t *testing.T) {
 
 func TestZmxAttachCommand(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "")
-	want := `zmx attach "my-session"`
+	cmd := b.AttachCommand("my-session", "").String()
+	want := `zmx attach my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -57,8 +57,8 @@ func TestZmxAttachCommand(t *testing.T) {
 
 func TestZmxAttachCommandWithDir(t *testing.T) {
 	b := New()
-	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `cd "/tmp/foo" && zmx attach "my-session"`
+	cmd := b.AttachCommand("my-session", "/tmp/foo").String()
+	want := `cd /tmp/foo && zmx attach my-session`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
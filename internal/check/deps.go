@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/custom"
+	"github.com/nerveband/zpick/internal/backend/plugin"
 )
 
 // DepStatus represents the installation status of a dependency.
@@ -29,6 +31,11 @@ type Result struct {
 	Arch              string    `json:"arch"`
 	Backend           string    `json:"backend,omitempty"`
 	AvailableBackends []string  `json:"available_backends,omitempty"`
+	Plugins           []string  `json:"plugins,omitempty"`
+	ConfigError       string    `json:"config_error,omitempty"` // why backends.conf couldn't be read
+
+	// Capabilities of each available backend, keyed by name.
+	Capabilities map[string]backend.Capabilities `json:"capabilities,omitempty"`
 }
 
 // JSON returns the result as indented JSON.
@@ -50,6 +57,20 @@ func Run() Result {
 
 	// Backend info
 	r.AvailableBackends = backend.Detect()
+	r.Plugins = plugin.Discover()
+	if _, err := custom.ReadConfig(); err != nil {
+		r.ConfigError = err.Error()
+	}
+	for _, name := range r.AvailableBackends {
+		b, err := backend.New(name)
+		if err != nil {
+			continue
+		}
+		if r.Capabilities == nil {
+			r.Capabilities = make(map[string]backend.Capabilities)
+		}
+		r.Capabilities[name] = b.Capabilities()
+	}
 	if name, err := backend.ReadBackendName(); err == nil && name != "" {
 		r.Backend = name
 	} else if len(r.AvailableBackends) == 1 {
@@ -107,6 +128,23 @@ func (r Result) PrintHuman() {
 	if len(r.AvailableBackends) > 0 {
 		fmt.Printf("Available: %s\n", strings.Join(r.AvailableBackends, ", "))
 	}
+	if len(r.Plugins) > 0 {
+		fmt.Printf("Plugins: %s\n", strings.Join(r.Plugins, ", "))
+	}
+	for _, name := range r.AvailableBackends {
+		if caps, ok := r.Capabilities[name]; ok {
+			fmt.Printf("  %s: %s\n", name, capabilityList(caps))
+		}
+	}
+}
+
+// capabilityList formats caps for display.
+func capabilityList(caps backend.Capabilities) string {
+	names := caps.Names()
+	if len(names) == 0 {
+		return "basic (list, attach, kill)"
+	}
+	return strings.Join(names, ", ")
 }
 
 // PrintGuide prints a guided installation walkthrough for missing dependencies.
@@ -122,7 +160,20 @@ func (r Result) PrintGuide() bool {
 		fmt.Printf("  \033[1;36mBackend:\033[0m %s\n", r.Backend)
 	}
 	if len(r.AvailableBackends) > 0 {
-		fmt.Printf("  \033[2mAvailable:\033[0m %s\n\n", strings.Join(r.AvailableBackends, ", "))
+		fmt.Printf("  \033[2mAvailable:\033[0m %s\n", strings.Join(r.AvailableBackends, ", "))
+		if len(r.Plugins) > 0 {
+			fmt.Printf("  \033[2mPlugins:\033[0m   %s\n", strings.Join(r.Plugins, ", "))
+		}
+		for _, name := range r.AvailableBackends {
+			if caps, ok := r.Capabilities[name]; ok {
+				fmt.Printf("  \033[2m  %s: %s\033[0m\n", name, capabilityList(caps))
+			}
+		}
+		fmt.Println()
+	}
+
+	if r.ConfigError != "" {
+		fmt.Printf("  \033[1;31m\u2717\033[0m %s\n\n", r.ConfigError)
 	}
 
 	// zmosh (required only if zmosh is the backend)
@@ -3,6 +3,8 @@ package check
 import (
 	"encoding/json"
 	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
 )
 
 func TestCheckResultJSON(t *testing.T) {
@@ -118,3 +120,32 @@ func TestDetectShell(t *testing.T) {
 		t.Error("expected non-empty shell")
 	}
 }
+
+func TestCheckJSONCapabilities(t *testing.T) {
+	result := Result{
+		AvailableBackends: []string{"tmux"},
+		Capabilities:      map[string]backend.Capabilities{"tmux": {Rename: true}},
+	}
+	j, err := result.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Capabilities map[string]map[string]bool `json:"capabilities"`
+	}
+	if err := json.Unmarshal([]byte(j), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if !raw.Capabilities["tmux"]["rename"] {
+		t.Errorf("capabilities = %v, want tmux rename", raw.Capabilities)
+	}
+}
+
+func TestCapabilityList(t *testing.T) {
+	if got := capabilityList(backend.Capabilities{Rename: true, PIDs: true}); got != "rename, pids" {
+		t.Errorf("capabilityList() = %q", got)
+	}
+	if got := capabilityList(backend.Capabilities{}); got != "basic (list, attach, kill)" {
+		t.Errorf("capabilityList() for no capabilities = %q", got)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/nerveband/zpick/internal/backend"
 	"github.com/nerveband/zpick/internal/picker"
+	"github.com/nerveband/zpick/internal/shell"
 	"golang.org/x/term"
 )
 
@@ -23,16 +24,16 @@ const (
 	timeout = 10 * time.Second
 )
 
-// Run shows the guard prompt and returns a shell command to eval, or empty string.
-func Run(b backend.Backend, argv []string) (string, error) {
+// Run shows the guard prompt and returns a command to eval, or a zero Command.
+func Run(b backend.Backend, argv []string) (shell.Command, error) {
 	// Already in a session — exit silently
 	if b.InSession() {
-		return "", nil
+		return shell.Command{}, nil
 	}
 
 	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
-		return "", nil
+		return shell.Command{}, nil
 	}
 	defer tty.Close()
 
@@ -48,7 +49,7 @@ func Run(b backend.Backend, argv []string) (string, error) {
 	case keyEnter:
 		return runPicker(tty, b, argv)
 	default:
-		return "", nil
+		return shell.Command{}, nil
 	}
 }
 
@@ -98,20 +99,17 @@ func waitForKey(tty *os.File, d time.Duration) keyAction {
 	}
 }
 
-func runPicker(tty *os.File, b backend.Backend, argv []string) (string, error) {
+func runPicker(tty *os.File, b backend.Backend, argv []string) (shell.Command, error) {
 	cmd, err := picker.Run(b, "")
-	if err != nil {
-		return "", err
-	}
-	if cmd == "" {
-		return "", nil
+	if err != nil || cmd.IsZero() {
+		return cmd, err
 	}
 
 	if len(argv) > 0 {
 		encoded := encodeArgv(argv)
 		if encoded != "" {
 			fmt.Fprintf(tty, "  %srun:%s %s\n", dim, reset, formatArgv(argv))
-			return fmt.Sprintf("ZPICK_AUTORUN=%s %s", encoded, cmd), nil
+			cmd.Env = append(cmd.Env, "ZPICK_AUTORUN="+encoded)
 		}
 	}
 
@@ -0,0 +1,151 @@
+// Package history records the sessions zp attaches to, so the picker can
+// sort by recent use.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// Entry is one attach, new session or switch performed through zp.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Name    string    `json:"name"`
+	Backend string    `json:"backend"` // the concrete backend, never "all"
+}
+
+// maxEntries is how much history is kept. The log is trimmed back to this
+// once it has grown to twice the size, so most writes are plain appends.
+const maxEntries = 500
+
+// Path returns the history log location.
+func Path() string {
+	return filepath.Join(backend.ConfigDir(), "history")
+}
+
+// Record appends an entry for the session name in backendName.
+func Record(name, backendName string) error {
+	line, err := json.Marshal(Entry{Time: time.Now().UTC(), Name: name, Backend: backendName})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(Path()), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(Path(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return trim()
+}
+
+// Read returns the log, oldest first. A missing log is empty; lines that
+// don't parse are skipped.
+func Read() ([]Entry, error) {
+	data, err := os.ReadFile(Path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) == nil && e.Name != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// trim rewrites the log with only the newest maxEntries once it has
+// doubled in size.
+func trim() error {
+	entries, err := Read()
+	if err != nil || len(entries) < 2*maxEntries {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, e := range entries[len(entries)-maxEntries:] {
+		line, _ := json.Marshal(e)
+		buf.Write(append(line, '\n'))
+	}
+	tmp := Path() + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, Path())
+}
+
+// Key identifies a session in LastUsed.
+func Key(backendName, name string) string {
+	return backendName + "\x00" + name
+}
+
+// Recency returns a lastUsed function for backend.SortSessions. Sessions
+// without a Backend, as in a single backend's listing, belong to
+// defaultBackend.
+func Recency(defaultBackend string) func(backend.Session) time.Time {
+	entries, _ := Read()
+	last := LastUsed(entries)
+	return func(s backend.Session) time.Time {
+		name := s.Backend
+		if name == "" {
+			name = defaultBackend
+		}
+		return last[Key(name, s.Name)]
+	}
+}
+
+// LastUsed returns when each session was last used, keyed by Key.
+func LastUsed(entries []Entry) map[string]time.Time {
+	last := make(map[string]time.Time, len(entries))
+	for _, e := range entries {
+		k := Key(e.Backend, e.Name)
+		if e.Time.After(last[k]) {
+			last[k] = e.Time
+		}
+	}
+	return last
+}
+
+// Previous returns the session among sessions that was used most recently
+// through zp, other than current (a Key; empty when not in a session).
+// Sessions without a Backend belong to defaultBackend. Dead sessions are
+// passed over, since they can't be attached.
+func Previous(entries []Entry, sessions []backend.Session, defaultBackend, current string) (backend.Session, bool) {
+	index := make(map[string]int, len(sessions))
+	for i, s := range sessions {
+		name := s.Backend
+		if name == "" {
+			name = defaultBackend
+		}
+		index[Key(name, s.Name)] = i
+	}
+	for _, e := range slices.Backward(entries) {
+		k := Key(e.Backend, e.Name)
+		if k == current {
+			continue
+		}
+		if i, ok := index[k]; ok && !sessions[i].Dead {
+			return sessions[i], true
+		}
+	}
+	return backend.Session{}, false
+}
@@ -0,0 +1,109 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if entries, err := Read(); err != nil || entries != nil {
+		t.Fatalf("Read() without a log = %v, %v", entries, err)
+	}
+	if err := Record("api", "tmux"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Record("web", "zellij"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name != "api" || entries[1].Backend != "zellij" {
+		t.Fatalf("Read() = %+v", entries)
+	}
+	if time.Since(entries[1].Time) > time.Minute {
+		t.Errorf("entry time = %v, want about now", entries[1].Time)
+	}
+}
+
+func TestReadSkipsBadLines(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	Record("api", "tmux")
+	f, _ := os.OpenFile(Path(), os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("not json\n{\"name\":\"\"}\n")
+	f.Close()
+
+	entries, _ := Read()
+	if len(entries) != 1 {
+		t.Errorf("Read() = %+v, want only the valid entry", entries)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for i := range 2 * maxEntries {
+		if err := Record(fmt.Sprintf("s%d", i), "tmux"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, _ := Read()
+	if len(entries) != maxEntries {
+		t.Fatalf("after %d records the log has %d entries, want %d", 2*maxEntries, len(entries), maxEntries)
+	}
+	if last := entries[len(entries)-1].Name; last != fmt.Sprintf("s%d", 2*maxEntries-1) {
+		t.Errorf("newest entry = %s", last)
+	}
+}
+
+func TestLastUsed(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := LastUsed([]Entry{
+		{Time: t0, Name: "api", Backend: "tmux"},
+		{Time: t0.Add(time.Hour), Name: "api", Backend: "tmux"},
+		{Time: t0.Add(2 * time.Hour), Name: "api", Backend: "zellij"},
+	})
+	if got := last[Key("tmux", "api")]; !got.Equal(t0.Add(time.Hour)) {
+		t.Errorf("tmux/api last used %v", got)
+	}
+	if len(last) != 2 {
+		t.Errorf("LastUsed() has %d keys, want 2", len(last))
+	}
+}
+
+func TestPrevious(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: t0, Name: "api", Backend: "tmux"},
+		{Time: t0.Add(time.Minute), Name: "gone", Backend: "tmux"},
+		{Time: t0.Add(2 * time.Minute), Name: "web", Backend: "zellij"},
+		{Time: t0.Add(3 * time.Minute), Name: "dead", Backend: "tmux"},
+		{Time: t0.Add(4 * time.Minute), Name: "db", Backend: "tmux"},
+	}
+	sessions := []backend.Session{{Name: "api"}, {Name: "db"}, {Name: "web"}, {Name: "dead", Dead: true}}
+
+	// In db, the previous session is the newest other one still running
+	// in this backend: web is zellij's, gone isn't listed, dead is dead.
+	if s, ok := Previous(entries, sessions, "tmux", Key("tmux", "db")); !ok || s.Name != "api" {
+		t.Errorf("Previous() from db = %v, %v; want api", s.Name, ok)
+	}
+	// Outside a session it is simply the last one used.
+	if s, ok := Previous(entries, sessions, "tmux", ""); !ok || s.Name != "db" {
+		t.Errorf("Previous() outside = %v, %v; want db", s.Name, ok)
+	}
+	// Aggregated listings carry their backend.
+	agg := []backend.Session{{Name: "db", Backend: "tmux"}, {Name: "web", Backend: "zellij"}}
+	if s, ok := Previous(entries, agg, "all", Key("tmux", "db")); !ok || s.Name != "web" {
+		t.Errorf("Previous() aggregated = %v, %v; want web", s.Name, ok)
+	}
+	if _, ok := Previous(entries, sessions[1:2], "tmux", Key("tmux", "db")); ok {
+		t.Error("Previous() with only the current session should find nothing")
+	}
+}
@@ -36,8 +36,18 @@ func GenerateFishHookBlock(apps []string) string {
 	b.WriteString(blockStart)
 	b.WriteByte('\n')
 
-	// Picker function: eval the command zp outputs
-	b.WriteString("function zp\n  eval (command zp)\nend\n")
+	// Tell zp which quoting rules the evals below use
+	b.WriteString("set -gx ZPICK_SHELL fish\n")
+
+	// Picker function: eval the command the picker outputs; subcommands
+	// like zp last run as they are
+	b.WriteString("function zp\n")
+	b.WriteString("  if test (count $argv) -eq 0; or contains -- $argv[1] --all --host --remote\n")
+	b.WriteString("    eval (command zp $argv)\n")
+	b.WriteString("  else\n")
+	b.WriteString("    command zp $argv\n")
+	b.WriteString("  end\n")
+	b.WriteString("end\n")
 
 	// Autorun
 	b.WriteString("# Auto-run: launch saved command when entering a new session\n")
@@ -46,9 +56,13 @@ func GenerateFishHookBlock(apps []string) string {
 	b.WriteString("  set -e ZPICK_AUTORUN\n")
 	b.WriteString("end\n")
 
-	// Switch-target: resume after in-session detach
-	b.WriteString("if test -f \"$HOME/.cache/zpick/switch-target\"\n")
-	b.WriteString("  eval (command zp resume)\n")
+	// Switch-target: resume after in-session detach. Targets are kept per
+	// terminal, keyed by its tty.
+	b.WriteString("set -l _zpick_tty (tty 2>/dev/null)\n")
+	b.WriteString("string match -q '/*' -- \"$_zpick_tty\"; or set _zpick_tty \"\"\n")
+	b.WriteString("set -l _zpick_dir \"$HOME/.cache/zpick/switch\"\n")
+	b.WriteString("if test -f \"$_zpick_dir/\"(string replace -a / - -- \"tty$_zpick_tty\"); or test -f \"$_zpick_dir/default\"\n")
+	b.WriteString("  eval (ZPICK_TTY=$_zpick_tty command zp resume)\n")
 	b.WriteString("end\n")
 
 	// Guard function + per-app wrappers (optional — only if apps configured)
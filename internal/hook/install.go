@@ -47,8 +47,12 @@ func GenerateHookBlock(apps []string) string {
 	b.WriteString(blockStart)
 	b.WriteByte('\n')
 
-	// Picker launcher: eval the command zp outputs
-	b.WriteString("zp() { eval \"$(command zp)\"; }\n")
+	// Tell zp which quoting rules the evals below use
+	b.WriteString("export ZPICK_SHELL=posix\n")
+
+	// Picker launcher: eval the command the picker outputs; subcommands
+	// like zp last run as they are
+	b.WriteString("zp() { if [[ $# -eq 0 || $1 == --all || $1 == --host || $1 == --remote ]]; then eval \"$(command zp \"$@\")\"; else command zp \"$@\"; fi; }\n")
 
 	// Autorun: defer to precmd so it runs after shell init (avoids p10k instant prompt conflict)
 	b.WriteString("if [[ -n \"$ZPICK_AUTORUN\" ]]; then\n")
@@ -60,13 +64,25 @@ func GenerateHookBlock(apps []string) string {
 	b.WriteString("  precmd_functions+=(_zpick_autorun)\n")
 	b.WriteString("fi\n")
 
-	// Switch-target: resume after in-session detach
-	b.WriteString("if [[ -f \"$HOME/.cache/zpick/switch-target\" ]]; then\n")
+	// Switch-target: resume after in-session detach. Targets are kept per
+	// terminal, keyed by its tty.
+	b.WriteString("_zpick_tty=\"${TTY:-$(tty 2>/dev/null)}\"\n")
+	b.WriteString("[[ \"$_zpick_tty\" == /* ]] || _zpick_tty=\"\"\n")
+	b.WriteString("_zpick_dir=\"$HOME/.cache/zpick/switch\"\n")
+	b.WriteString("if [[ -f \"$_zpick_dir/tty${_zpick_tty//\\//-}\" || -f \"$_zpick_dir/default\" ]]; then\n")
 	b.WriteString("  _zpick_switch() {\n")
-	b.WriteString("    precmd_functions=(${precmd_functions:#_zpick_switch})\n")
-	b.WriteString("    eval \"$(command zp resume)\"\n")
+	b.WriteString("    if [[ -n \"$ZSH_VERSION\" ]]; then\n")
+	b.WriteString("      precmd_functions=(${precmd_functions:#_zpick_switch})\n")
+	b.WriteString("    else\n")
+	b.WriteString("      PROMPT_COMMAND=\"${PROMPT_COMMAND#_zpick_switch;}\"\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    eval \"$(ZPICK_TTY=\"$_zpick_tty\" command zp resume)\"\n")
 	b.WriteString("  }\n")
-	b.WriteString("  precmd_functions+=(_zpick_switch)\n")
+	b.WriteString("  if [[ -n \"$ZSH_VERSION\" ]]; then\n")
+	b.WriteString("    precmd_functions+=(_zpick_switch)\n")
+	b.WriteString("  else\n")
+	b.WriteString("    PROMPT_COMMAND=\"_zpick_switch;$PROMPT_COMMAND\"\n")
+	b.WriteString("  fi\n")
 	b.WriteString("fi\n")
 
 	// Guard function + per-app wrappers (optional — only if apps configured)
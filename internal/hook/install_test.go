@@ -22,6 +22,9 @@ func TestGenerateHookBlock(t *testing.T) {
 	if !strings.Contains(block, "ZPICK_AUTORUN") {
 		t.Error("block should contain autorun check")
 	}
+	if !strings.Contains(block, "export ZPICK_SHELL=posix") {
+		t.Error("block should export ZPICK_SHELL for quoting")
+	}
 	if !strings.Contains(block, `claude() { _zpick_guard claude "$@"; }`) {
 		t.Error("block should contain claude function")
 	}
@@ -317,6 +320,9 @@ func TestGenerateFishHookBlock(t *testing.T) {
 	if !strings.Contains(block, "set -e ZPICK_AUTORUN") {
 		t.Error("fish block should use set -e to unset ZPICK_AUTORUN")
 	}
+	if !strings.Contains(block, "set -gx ZPICK_SHELL fish") {
+		t.Error("fish block should export ZPICK_SHELL for quoting")
+	}
 	if !strings.Contains(block, `test -z "$ZMX_SESSION"`) {
 		t.Error("fish block should check ZMX_SESSION")
 	}
@@ -467,8 +473,17 @@ func TestRemoveFromFileNotFound(t *testing.T) {
 func TestGenerateHookBlockContainsSwitchTarget(t *testing.T) {
 	block := GenerateHookBlock([]string{"claude"})
 
-	if !strings.Contains(block, "switch-target") {
-		t.Error("block should contain switch-target check")
+	if !strings.Contains(block, ".cache/zpick/switch") {
+		t.Error("block should contain switch target check")
+	}
+	if strings.Contains(block, "ZPICK_CLIENT") {
+		t.Error("block should key targets by tty, not an inherited token")
+	}
+	if !strings.Contains(block, "ZPICK_TTY=\"$_zpick_tty\" command zp resume") {
+		t.Error("block should pass its tty to zp resume")
+	}
+	if !strings.Contains(block, "PROMPT_COMMAND=\"_zpick_switch;") {
+		t.Error("block should resume from bash's PROMPT_COMMAND")
 	}
 	if !strings.Contains(block, "_zpick_switch") {
 		t.Error("block should contain _zpick_switch function")
@@ -481,8 +496,14 @@ func TestGenerateHookBlockContainsSwitchTarget(t *testing.T) {
 func TestGenerateFishHookBlockContainsSwitchTarget(t *testing.T) {
 	block := GenerateFishHookBlock([]string{"claude"})
 
-	if !strings.Contains(block, "switch-target") {
-		t.Error("fish block should contain switch-target check")
+	if !strings.Contains(block, ".cache/zpick/switch") {
+		t.Error("fish block should contain switch target check")
+	}
+	if strings.Contains(block, "ZPICK_CLIENT") {
+		t.Error("fish block should key targets by tty, not an inherited token")
+	}
+	if !strings.Contains(block, "ZPICK_TTY=$_zpick_tty command zp resume") {
+		t.Error("fish block should pass its tty to zp resume")
 	}
 	if !strings.Contains(block, "zp resume") {
 		t.Error("fish block should reference zp resume command")
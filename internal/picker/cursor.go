@@ -0,0 +1,55 @@
+package picker
+
+import "github.com/nerveband/zpick/internal/backend"
+
+// move applies a navigation key to the highlighted row and the page, and
+// reports whether k was one. visible is the filtered list; the highlight
+// is an index into it, so it can cross pages. An open preview follows the
+// highlight.
+func (s *pickerState) move(k keyPress, visible []backend.Session) bool {
+	n := len(visible)
+	pageStart := s.page * MaxSessions
+	cur := s.selected
+
+	switch {
+	case k.kind == keyUp:
+		if cur < 0 {
+			cur = pageStart + len(pageOf(visible, s.page))
+		}
+		cur--
+	case k.kind == keyDown:
+		if cur < 0 {
+			cur = pageStart - 1
+		}
+		cur++
+	case k.kind == keyHome:
+		cur = 0
+	case k.kind == keyEnd:
+		cur = n - 1
+	case k.kind == keyPageUp:
+		cur = max(cur, pageStart) - MaxSessions
+	case k.kind == keyPageDown:
+		cur = max(cur, pageStart) + MaxSessions
+	case k.kind == keyLeft || k.kind == keyRune && k.r == '[':
+		s.page = clampPage(s.page-1, n)
+		s.selected = -1
+		return true
+	case k.kind == keyRight || k.kind == keyRune && k.r == ']':
+		s.page = clampPage(s.page+1, n)
+		s.selected = -1
+		return true
+	default:
+		return false
+	}
+
+	if n == 0 {
+		return true
+	}
+	cur = max(0, min(cur, n-1))
+	s.selected = cur
+	s.page = cur / MaxSessions
+	if s.previewName != "" {
+		s.previewName, s.previewBackend = visible[cur].Name, visible[cur].Backend
+	}
+	return true
+}
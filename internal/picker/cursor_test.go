@@ -0,0 +1,52 @@
+package picker
+
+import (
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestMoveCursor(t *testing.T) {
+	visible := make([]backend.Session, MaxSessions+5)
+	for i := range visible {
+		visible[i].Name = string(rune('A' + i))
+	}
+	s := pickerState{selected: -1}
+
+	s.move(keyPress{kind: keyDown}, visible)
+	if s.selected != 0 {
+		t.Errorf("first Down selected %d, want 0", s.selected)
+	}
+	s.move(keyPress{kind: keyUp}, visible)
+	if s.selected != 0 {
+		t.Errorf("Up at the top selected %d, want 0", s.selected)
+	}
+	s.move(keyPress{kind: keyPageDown}, visible)
+	if s.selected != MaxSessions || s.page != 1 {
+		t.Errorf("PageDown: selected %d page %d, want %d page 1", s.selected, s.page, MaxSessions)
+	}
+	s.move(keyPress{kind: keyEnd}, visible)
+	if s.selected != len(visible)-1 {
+		t.Errorf("End selected %d, want %d", s.selected, len(visible)-1)
+	}
+	s.move(keyPress{kind: keyRune, r: '['}, visible)
+	if s.page != 0 || s.selected != -1 {
+		t.Errorf("[: page %d selected %d, want page 0 and no highlight", s.page, s.selected)
+	}
+	s.move(keyPress{kind: keyUp}, visible)
+	if s.selected != MaxSessions-1 {
+		t.Errorf("first Up selected %d, want the last row of the page", s.selected)
+	}
+	if s.move(keyPress{kind: keyRune, r: 'j'}, visible) {
+		t.Error("j is a session label, not navigation")
+	}
+}
+
+func TestMoveCursorFollowsPreview(t *testing.T) {
+	visible := []backend.Session{{Name: "a"}, {Name: "b", Backend: "tmux"}}
+	s := pickerState{selected: 0, previewName: "a"}
+	s.move(keyPress{kind: keyDown}, visible)
+	if s.previewName != "b" || s.previewBackend != "tmux" {
+		t.Errorf("preview = %s/%s, want tmux/b", s.previewBackend, s.previewName)
+	}
+}
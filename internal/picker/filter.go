@@ -0,0 +1,67 @@
+package picker
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// filterSessions returns the sessions that fuzzy-match query, best first.
+// Name matches rank above sessions that only match on their directory;
+// ties keep the backend's order.
+func filterSessions(sessions []backend.Session, query string) []backend.Session {
+	if query == "" {
+		return sessions
+	}
+	type match struct {
+		s     backend.Session
+		score int
+	}
+	var matches []match
+	for _, s := range sessions {
+		if score, ok := fuzzyScore(query, s.Name); ok {
+			matches = append(matches, match{s, score + 1000})
+		} else if score, ok := fuzzyScore(query, s.StartedIn); ok {
+			matches = append(matches, match{s, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	filtered := make([]backend.Session, len(matches))
+	for i, m := range matches {
+		filtered[i] = m.s
+	}
+	return filtered
+}
+
+// fuzzyScore reports whether pattern's characters appear in order in s,
+// ignoring case, and scores the match. Runs of consecutive characters and
+// matches at the start of s or of a word score higher; gaps score lower.
+func fuzzyScore(pattern, s string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(s))
+	score, pi, last := 0, 0, -1
+	for i := 0; i < len(t) && pi < len(p); i++ {
+		if t[i] != p[pi] {
+			continue
+		}
+		score += 2
+		switch {
+		case i == 0:
+			score += 5
+		case strings.ContainsRune("-_./ ~", t[i-1]):
+			score += 3
+		case last == i-1:
+			score += 2
+		case last >= 0:
+			score -= min(i-last-1, 3)
+		}
+		last = i
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	return score, true
+}
@@ -0,0 +1,55 @@
+package picker
+
+import (
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("apsv", "api-server"); !ok {
+		t.Error("apsv should match api-server")
+	}
+	if _, ok := fuzzyScore("API", "api-server"); !ok {
+		t.Error("matching should ignore case")
+	}
+	if _, ok := fuzzyScore("sa", "api-server"); ok {
+		t.Error("sa should not match api-server (out of order)")
+	}
+	prefix, _ := fuzzyScore("web", "web-2")
+	scattered, _ := fuzzyScore("web", "wide-cobweb")
+	if prefix <= scattered {
+		t.Errorf("prefix match scored %d, scattered match %d", prefix, scattered)
+	}
+}
+
+func TestFilterSessions(t *testing.T) {
+	sessions := []backend.Session{
+		{Name: "notes", StartedIn: "/src/migrate"},
+		{Name: "db-migrate", StartedIn: "/src/db"},
+		{Name: "frontend", StartedIn: "/src/web"},
+		{Name: "migrations", StartedIn: "/src/db"},
+	}
+
+	got := filterSessions(sessions, "migr")
+	var names []string
+	for _, s := range got {
+		names = append(names, s.Name)
+	}
+	want := []string{"migrations", "db-migrate", "notes"}
+	if len(names) != len(want) {
+		t.Fatalf("filterSessions(migr) = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("filterSessions(migr) = %v, want %v", names, want)
+		}
+	}
+
+	if got := filterSessions(sessions, ""); len(got) != len(sessions) {
+		t.Errorf("empty query kept %d of %d sessions", len(got), len(sessions))
+	}
+	if got := filterSessions(sessions, "zzz"); len(got) != 0 {
+		t.Errorf("filterSessions(zzz) = %v, want none", got)
+	}
+}
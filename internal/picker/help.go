@@ -11,7 +11,8 @@ import (
 )
 
 // showHelpConfig renders the help/config screen on tty.
-// Handles 'b' to cycle backend, 'u' to toggle UDP. Esc returns to picker.
+// Handles 'b' to cycle backend, 'u' to toggle UDP, 'l' for key mode and
+// 's' for sort mode. Esc returns to picker.
 func showHelpConfig(tty *os.File, b backend.Backend, version string) {
 	for {
 		renderHelp(tty, b, version)
@@ -43,6 +44,8 @@ func showHelpConfig(tty *os.File, b backend.Backend, version string) {
 			toggleUDP(tty)
 		case 'l':
 			toggleKeyMode()
+		case 's':
+			cycleSortMode()
 		}
 	}
 }
@@ -61,8 +64,23 @@ func renderHelp(tty *os.File, b backend.Backend, version string) {
 	}
 	fmt.Fprintf(tty, "    %s%s%s  attach session       %senter%s  new session\n", boldYel, keyRange, reset, boldGrn, reset)
 	fmt.Fprintf(tty, "    %sc%s        custom name           %sd%s      +date name\n", magenta, reset, cyan, reset)
-	fmt.Fprintf(tty, "    %sz%s        pick dir (zoxide)     %sk%s      kill session\n", magenta, reset, red, reset)
+	fmt.Fprintf(tty, "    %sz%s        pick dir (zoxide)     %sx%s      kill session\n", magenta, reset, red, reset)
 	fmt.Fprintf(tty, "    %sh%s        this screen           %sesc%s    skip\n", cyan, reset, yellow, reset)
+	if b.Capabilities().Rename {
+		fmt.Fprintf(tty, "    %sr%s        rename session\n", cyan, reset)
+	}
+	fmt.Fprintf(tty, "    %s↑↓ jk%s    move highlight (also ^P/^N, home/end, pgup/pgdn), enter attaches\n", cyan, reset)
+	fmt.Fprintf(tty, "    %s[ ]%s      previous/next page (over %d sessions, also ←→)\n", cyan, reset, MaxSessions)
+	fmt.Fprintf(tty, "    %s/%s        filter sessions (fuzzy, name or dir)\n", cyan, reset)
+	fmt.Fprintf(tty, "    %sx space%s  mark several sessions, enter kills them\n", red, reset)
+	fmt.Fprintf(tty, "    %s!%s        protect/unprotect session from kills\n", yellow, reset)
+	fmt.Fprintf(tty, "    %s+%s        pin/unpin session (pinned keep the first keys)\n", cyan, reset)
+	fmt.Fprintf(tty, "    %s-%s        back to the previous session\n", cyan, reset)
+	if b.Capabilities().Capture {
+		fmt.Fprintf(tty, "    %stab%s      preview session screen\n", cyan, reset)
+	} else {
+		fmt.Fprintf(tty, "    %stab%s      session details\n", cyan, reset)
+	}
 	fmt.Fprintln(tty)
 
 	// Config section
@@ -74,6 +92,9 @@ func renderHelp(tty *os.File, b backend.Backend, version string) {
 
 	// Backend
 	available := backend.Detect()
+	if len(available) >= 2 {
+		available = append(available, backend.AllBackends)
+	}
 	availStr := strings.Join(available, ", ")
 	fmt.Fprintf(tty, "    %sb%s  backend    %s%-12s%s %s[%s]%s\n",
 		magenta, reset, boldWht, b.Name(), reset, dim, availStr, reset)
@@ -109,6 +130,9 @@ func renderHelp(tty *os.File, b backend.Backend, version string) {
 	}
 	fmt.Fprintf(tty, "    %sl%s  keys       %s%-12s%s %s[%s]%s\n", magenta, reset, boldWht, keyMode, reset, dim, keyLabel, reset)
 
+	// Sort
+	fmt.Fprintf(tty, "    %ss%s  sort       %s%-12s%s %s[%s]%s\n", magenta, reset, boldWht, backend.ReadSortMode(), reset, dim, strings.Join(backend.SortModes, ", "), reset)
+
 	fmt.Fprintln(tty)
 	fmt.Fprintf(tty, "  %s%s%s  %sgithub.com/nerveband/zpick%s\n", dim, version, reset, dim, reset)
 	fmt.Fprintf(tty, "  %sesc%s %sback%s\n", yellow, reset, dim, reset)
@@ -119,6 +143,7 @@ func cycleBackend(tty *os.File, current backend.Backend) backend.Backend {
 	if len(available) < 2 {
 		return current
 	}
+	available = append(available, backend.AllBackends)
 
 	// Find current index and cycle to next
 	currentName := current.Name()
@@ -163,6 +188,19 @@ func toggleKeyMode() {
 	LoadKeyMode(next)
 }
 
+func cycleSortMode() {
+	modes := backend.SortModes
+	current := backend.ReadSortMode()
+	next := modes[0]
+	for i, m := range modes {
+		if m == current {
+			next = modes[(i+1)%len(modes)]
+			break
+		}
+	}
+	backend.SetSortMode(next)
+}
+
 // readGuardApps reads guard.conf from the config dir.
 // Returns defaults if the file doesn't exist.
 func readGuardApps(configDir string) []string {
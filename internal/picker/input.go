@@ -0,0 +1,118 @@
+package picker
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota // a plain byte, in keyPress.r
+	keyEnter
+	keyEscape
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyPageUp
+	keyPageDown
+	keyUnknown // an escape sequence we don't handle
+)
+
+type keyPress struct {
+	kind keyKind
+	r    byte
+}
+
+// escapeSequences maps what terminals send for navigation keys, in both
+// normal (ESC [) and application (ESC O) cursor mode.
+var escapeSequences = map[string]keyKind{
+	"[A": keyUp, "OA": keyUp,
+	"[B": keyDown, "OB": keyDown,
+	"[C": keyRight, "OC": keyRight,
+	"[D": keyLeft, "OD": keyLeft,
+	"[H": keyHome, "OH": keyHome, "[1~": keyHome, "[7~": keyHome,
+	"[F": keyEnd, "OF": keyEnd, "[4~": keyEnd, "[8~": keyEnd,
+	"[5~": keyPageUp,
+	"[6~": keyPageDown,
+}
+
+// decodeKey turns the bytes of one read from the terminal into a key.
+// A lone ESC is Escape; anything longer starting with ESC is a sequence.
+func decodeKey(b []byte) keyPress {
+	if len(b) == 0 {
+		return keyPress{kind: keyUnknown}
+	}
+	switch b[0] {
+	case 27:
+		if len(b) == 1 {
+			return keyPress{kind: keyEscape}
+		}
+		if k, ok := escapeSequences[string(b[1:])]; ok {
+			return keyPress{kind: k}
+		}
+		return keyPress{kind: keyUnknown}
+	case 13, 10:
+		return keyPress{kind: keyEnter}
+	case 14: // Ctrl-N
+		return keyPress{kind: keyDown}
+	case 16: // Ctrl-P
+		return keyPress{kind: keyUp}
+	case 'j': // vi-style, never a session label
+		return keyPress{kind: keyDown}
+	case 'k':
+		return keyPress{kind: keyUp}
+	}
+	return keyPress{kind: keyRune, r: b[0]}
+}
+
+// readKey reads one keypress from tty in raw mode. With a positive
+// timeout it gives up after that long and reports false.
+func readKey(tty *os.File, timeout time.Duration) (keyPress, bool, error) {
+	oldState, err := term.MakeRaw(int(tty.Fd()))
+	if err != nil {
+		return keyPress{}, false, fmt.Errorf("failed to set raw mode: %w", err)
+	}
+	defer term.Restore(int(tty.Fd()), oldState)
+
+	if timeout > 0 {
+		ready, err := waitReadable(int(tty.Fd()), timeout)
+		if err != nil {
+			return keyPress{}, false, err
+		}
+		if !ready {
+			return keyPress{}, false, nil
+		}
+	}
+
+	buf := make([]byte, 8)
+	n, err := tty.Read(buf)
+	if err != nil {
+		return keyPress{}, false, err
+	}
+	return decodeKey(buf[:n]), true, nil
+}
+
+// waitReadable waits up to timeout for fd to have input. It uses select
+// rather than poll, which macOS doesn't support on terminal devices.
+// An interrupted wait reports false so the caller simply polls again.
+func waitReadable(fd int, timeout time.Duration) (bool, error) {
+	var fds unix.FdSet
+	fds.Set(fd)
+	tv := unix.NsecToTimeval(timeout.Nanoseconds())
+	n, err := unix.Select(fd+1, &fds, nil, nil, &tv)
+	if err == unix.EINTR {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return n > 0 && fds.IsSet(fd), nil
+}
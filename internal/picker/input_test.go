@@ -0,0 +1,50 @@
+package picker
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDecodeKey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want keyPress
+	}{
+		{"\x1b", keyPress{kind: keyEscape}},
+		{"\r", keyPress{kind: keyEnter}},
+		{"\x1b[A", keyPress{kind: keyUp}},
+		{"\x1bOB", keyPress{kind: keyDown}},
+		{"\x1b[5~", keyPress{kind: keyPageUp}},
+		{"\x1b[F", keyPress{kind: keyEnd}},
+		{"\x1b[1~", keyPress{kind: keyHome}},
+		{"\x0e", keyPress{kind: keyDown}},
+		{"j", keyPress{kind: keyDown}},
+		{"k", keyPress{kind: keyUp}},
+		{"\x1b[15~", keyPress{kind: keyUnknown}}, // F5
+		{"7", keyPress{kind: keyRune, r: '7'}},
+		{"\t", keyPress{kind: keyRune, r: '\t'}},
+	}
+	for _, tt := range tests {
+		if got := decodeKey([]byte(tt.in)); got != tt.want {
+			t.Errorf("decodeKey(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWaitReadable(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if ready, err := waitReadable(int(r.Fd()), 10*time.Millisecond); err != nil || ready {
+		t.Errorf("waitReadable() on an empty pipe = %v, %v; want false", ready, err)
+	}
+	w.Write([]byte("j"))
+	if ready, err := waitReadable(int(r.Fd()), time.Second); err != nil || !ready {
+		t.Errorf("waitReadable() with input = %v, %v; want true", ready, err)
+	}
+}
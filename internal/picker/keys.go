@@ -1,17 +1,21 @@
 package picker
 
+import "github.com/nerveband/zpick/internal/backend"
+
 const (
-	// numbersFirst is the default key sequence: digits 1-9, then letters (skipping 'c' and 'k').
-	numbersFirst = "123456789abdefghijlmnopqrstuvwxy"
-	// lettersFirst puts letters before digits (still skipping 'c' and 'k').
-	lettersFirst = "abdefghijlmnopqrstuvwxy123456789"
+	// numbersFirst is the default key sequence: digits 1-9, then letters (skipping 'c', 'j', 'k', 'r' and 'x').
+	numbersFirst = "123456789abdefghilmnopqstuvwy"
+	// lettersFirst puts letters before digits (still skipping 'c', 'j', 'k', 'r' and 'x').
+	lettersFirst = "abdefghilmnopqstuvwy123456789"
 )
 
 // keyChars maps session indices to keypress characters.
-// Note: 'c' is reserved for custom name, 'k' is reserved for kill mode.
+// Note: 'c' is reserved for custom name, 'j' and 'k' for moving the
+// highlight, 'r' for rename mode and 'x' for kill mode.
 var keyChars = []byte(numbersFirst)
 
-// MaxSessions is the maximum number of sessions the picker can display.
+// MaxSessions is the number of sessions on one page of the picker; longer
+// lists are paged and every page reuses the same keys.
 var MaxSessions = len(keyChars)
 
 // LoadKeyMode sets the key character sequence based on the given mode.
@@ -25,7 +29,7 @@ func LoadKeyMode(mode string) {
 	MaxSessions = len(keyChars)
 }
 
-// KeyForIndex returns the key character for a session index.
+// KeyForIndex returns the key character for an index on the current page.
 func KeyForIndex(index int) byte {
 	if index < 0 || index >= len(keyChars) {
 		return '?'
@@ -33,7 +37,7 @@ func KeyForIndex(index int) byte {
 	return keyChars[index]
 }
 
-// IndexForKey returns the session index for a key character.
+// IndexForKey returns the index on the current page for a key character.
 func IndexForKey(key byte) (int, bool) {
 	for i, k := range keyChars {
 		if k == key {
@@ -42,3 +46,27 @@ func IndexForKey(key byte) (int, bool) {
 	}
 	return -1, false
 }
+
+// pageCount returns how many pages n sessions fill; an empty list is one page.
+func pageCount(n int) int {
+	if n == 0 {
+		return 1
+	}
+	return (n + MaxSessions - 1) / MaxSessions
+}
+
+// clampPage keeps page within the pages that n sessions fill, e.g. after
+// sessions on the last page were killed.
+func clampPage(page, n int) int {
+	return max(0, min(page, pageCount(n)-1))
+}
+
+// pageOf returns the sessions on page. They are the ones the picker labels
+// KeyForIndex(0), KeyForIndex(1), ... while that page is shown.
+func pageOf(sessions []backend.Session, page int) []backend.Session {
+	start := page * MaxSessions
+	if start >= len(sessions) {
+		return nil
+	}
+	return sessions[start:min(start+MaxSessions, len(sessions))]
+}
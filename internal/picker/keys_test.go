@@ -1,6 +1,10 @@
 package picker
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
 
 func TestKeyForIndex(t *testing.T) {
 	tests := []struct {
@@ -38,19 +42,48 @@ func TestIndexForKey(t *testing.T) {
 	if !ok || idx != 9 {
 		t.Errorf("expected 9, got %d (ok=%v)", idx, ok)
 	}
-	_, ok = IndexForKey('c') // reserved
-	if ok {
-		t.Error("'c' should not be a valid session key")
-	}
-	_, ok = IndexForKey('k') // reserved
-	if ok {
-		t.Error("'k' should not be a valid session key")
+	for _, key := range []byte("cjkrx") { // reserved
+		if _, ok := IndexForKey(key); ok {
+			t.Errorf("'%c' should not be a valid session key", key)
+		}
 	}
 }
 
 func TestMaxSessions(t *testing.T) {
-	if MaxSessions != 32 {
-		t.Errorf("expected 32 max sessions, got %d", MaxSessions)
+	if MaxSessions != 29 {
+		t.Errorf("expected 29 max sessions, got %d", MaxSessions)
+	}
+}
+
+func TestPaging(t *testing.T) {
+	sessions := make([]backend.Session, 2*MaxSessions+3)
+	for i := range sessions {
+		sessions[i].Name = string(rune('A' + i))
+	}
+
+	if got := pageCount(len(sessions)); got != 3 {
+		t.Errorf("pageCount(%d) = %d, want 3", len(sessions), got)
+	}
+	if got := pageCount(0); got != 1 {
+		t.Errorf("pageCount(0) = %d, want 1", got)
+	}
+
+	second := pageOf(sessions, 1)
+	if len(second) != MaxSessions || second[0].Name != sessions[MaxSessions].Name {
+		t.Errorf("pageOf(1) starts at %q with %d sessions", second[0].Name, len(second))
+	}
+	if last := pageOf(sessions, 2); len(last) != 3 {
+		t.Errorf("pageOf(2) has %d sessions, want 3", len(last))
+	}
+	if got := pageOf(sessions, 3); got != nil {
+		t.Errorf("pageOf(3) = %v, want nil", got)
+	}
+
+	if got := clampPage(2, MaxSessions+1); got != 1 {
+		t.Errorf("clampPage(2) with 2 pages = %d, want 1", got)
+	}
+	if got := clampPage(-1, 5); got != 0 {
+		t.Errorf("clampPage(-1) = %d, want 0", got)
 	}
 }
 
@@ -94,13 +127,10 @@ func TestLoadKeyMode_LettersSkipsReserved(t *testing.T) {
 	LoadKeyMode("letters")
 	defer LoadKeyMode("numbers")
 
-	_, ok := IndexForKey('c')
-	if ok {
-		t.Error("'c' should still be reserved in letters mode")
-	}
-	_, ok = IndexForKey('k')
-	if ok {
-		t.Error("'k' should still be reserved in letters mode")
+	for _, key := range []byte("cjkrx") {
+		if _, ok := IndexForKey(key); ok {
+			t.Errorf("'%c' should still be reserved in letters mode", key)
+		}
 	}
 }
 
@@ -108,7 +138,7 @@ func TestLoadKeyMode_LettersMaxSessions(t *testing.T) {
 	LoadKeyMode("letters")
 	defer LoadKeyMode("numbers")
 
-	if MaxSessions != 32 {
-		t.Errorf("expected 32 max sessions in letters mode, got %d", MaxSessions)
+	if MaxSessions != 29 {
+		t.Errorf("expected 29 max sessions in letters mode, got %d", MaxSessions)
 	}
 }
@@ -0,0 +1,17 @@
+package picker
+
+import (
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/history"
+)
+
+// previous returns the session - jumps back to: the one used most recently
+// through zp, other than the one we're in.
+func previous(b backend.Backend, sessions []backend.Session, currentSession, currentBackend string) (backend.Session, bool) {
+	entries, _ := history.Read()
+	var current string
+	if currentSession != "" {
+		current = history.Key(currentBackend, currentSession)
+	}
+	return history.Previous(entries, sessions, b.Name(), current)
+}
@@ -0,0 +1,27 @@
+package picker
+
+import (
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/history"
+)
+
+func TestPrevious(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	b := &mockBackend{name: "tmux"}
+	sessions := []backend.Session{{Name: "api"}, {Name: "web"}}
+
+	if _, ok := previous(b, sessions, "web", "tmux"); ok {
+		t.Error("previous() without history should find nothing")
+	}
+	history.Record("api", "tmux")
+	history.Record("web", "tmux")
+
+	if s, ok := previous(b, sessions, "web", "tmux"); !ok || s.Name != "api" {
+		t.Errorf("previous() in web = %q, %v; want api", s.Name, ok)
+	}
+	if s, ok := previous(b, sessions, "", ""); !ok || s.Name != "web" {
+		t.Errorf("previous() outside a session = %q, %v; want web", s.Name, ok)
+	}
+}
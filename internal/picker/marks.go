@@ -0,0 +1,77 @@
+package picker
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// enterMarkMode lets the user mark several sessions and kill them
+// together. Labels (or space on the highlighted row) toggle a mark, the
+// cursor keys move and page as usual, Enter asks to kill the marked set and
+// Esc drops the marks.
+func enterMarkMode(tty *os.File, b backend.Backend, sessions, visible []backend.Session, currentSession, currentBackend string, state *pickerState) (Action, error) {
+	state.marked = map[string]bool{}
+	for {
+		drawPicker(tty, b, sessions, visible, currentSession, currentBackend, state, drawMark)
+		fmt.Fprintf(tty, "  %skill%s ", boldRed, reset)
+
+		k, _, err := readKey(tty, 0)
+		if err != nil {
+			fmt.Fprintln(tty)
+			return Action{}, err
+		}
+		if state.move(k, visible) {
+			continue
+		}
+
+		switch {
+		case k.kind == keyEscape:
+			state.marked = nil
+			return Action{Type: ActionKill}, nil
+		case k.kind == keyEnter:
+			fmt.Fprintln(tty)
+			return Action{Type: ActionKillMarked}, nil
+		case k.kind != keyRune:
+			continue
+		case k.r == ' ':
+			if state.selected >= 0 {
+				state.toggleMark(visible[state.selected])
+			}
+		default:
+			onPage := pageOf(visible, state.page)
+			if idx, ok := IndexForKey(k.r); ok && idx < len(onPage) {
+				state.toggleMark(onPage[idx])
+			}
+		}
+	}
+}
+
+// toggleMark marks or unmarks s for killing. Sessions that aren't running
+// can't be marked.
+func (s *pickerState) toggleMark(sess backend.Session) {
+	key := sessionKey(sess)
+	switch {
+	case s.gone[key] || s.placeholder(sess):
+	case s.marked[key]:
+		delete(s.marked, key)
+	default:
+		s.marked[key] = true
+	}
+}
+
+// killSessions kills sessions in parallel, each through its own backend,
+// and returns the error of each kill in the order of sessions.
+func killSessions(b backend.Backend, sessions []backend.Session) []error {
+	errs := make([]error, len(sessions))
+	var wg sync.WaitGroup
+	for i, s := range sessions {
+		wg.Go(func() {
+			errs[i] = owner(b, s.Backend).Kill(s.Name)
+		})
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,36 @@
+package picker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestKillSessions(t *testing.T) {
+	b := &mockBackend{name: "tmux", failKill: "db", killErr: errors.New("no such session")}
+	errs := killSessions(b, listing("api", "db", "web"))
+	if len(errs) != 3 || errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Errorf("killSessions() = %v, want only db to fail", errs)
+	}
+}
+
+func TestToggleMark(t *testing.T) {
+	api, web, notes := backend.Session{Name: "api"}, backend.Session{Name: "web"}, backend.Session{Name: "notes"}
+	state := pickerState{
+		marked: map[string]bool{},
+		gone:   map[string]bool{sessionKey(web): true},
+		pinned: map[string]bool{sessionKey(notes): true},
+	}
+
+	state.toggleMark(api)
+	state.toggleMark(web)
+	state.toggleMark(notes)
+	if len(state.marked) != 1 || !state.marked[sessionKey(api)] {
+		t.Errorf("marked = %v, want only api (web is gone, notes isn't running)", state.marked)
+	}
+	state.toggleMark(api)
+	if len(state.marked) != 0 {
+		t.Errorf("second toggle should unmark api, marked = %v", state.marked)
+	}
+}
@@ -0,0 +1,53 @@
+package picker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// relativeAge formats how long ago t was, e.g. "now", "5m", "3h", "2d".
+func relativeAge(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
+
+// sessionMeta returns the age, task status and running process shown at
+// the end of a picker row, or "" when none of them is known. Last activity
+// wins over creation time when both are known.
+func sessionMeta(s backend.Session, now time.Time) string {
+	meta := ""
+	switch {
+	case !s.LastActivity.IsZero():
+		meta = fmt.Sprintf("%s%s ago%s", dim, relativeAge(s.LastActivity, now), reset)
+	case !s.CreatedAt.IsZero():
+		meta = fmt.Sprintf("%sup %s%s", dim, relativeAge(s.CreatedAt, now), reset)
+	}
+	if s.TaskEnded() {
+		status := fmt.Sprintf("%sexited%s", green, reset)
+		if s.TaskExitCode != 0 {
+			status = fmt.Sprintf("%sexit %d%s", red, s.TaskExitCode, reset)
+		}
+		if meta != "" {
+			meta += " "
+		}
+		meta += status
+	}
+	if s.Process != nil {
+		if meta != "" {
+			meta += " "
+		}
+		meta += fmt.Sprintf("%s%s%s", cyan, s.Process.Summary(), reset)
+	}
+	return meta
+}
@@ -0,0 +1,58 @@
+package picker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/procinfo"
+)
+
+func TestRelativeAge(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[time.Duration]string{
+		10 * time.Second:             "now",
+		5 * time.Minute:              "5m",
+		3*time.Hour + 59*time.Minute: "3h",
+		50 * time.Hour:               "2d",
+	}
+	for ago, want := range tests {
+		if got := relativeAge(now.Add(-ago), now); got != want {
+			t.Errorf("relativeAge(%v ago) = %q, want %q", ago, got, want)
+		}
+	}
+}
+
+func TestSessionMeta(t *testing.T) {
+	now := time.Now()
+
+	if got := sessionMeta(backend.Session{Name: "x"}, now); got != "" {
+		t.Errorf("no metadata should render nothing, got %q", got)
+	}
+
+	s := backend.Session{CreatedAt: now.Add(-2 * time.Hour)}
+	if got := sessionMeta(s, now); !strings.Contains(got, "up 2h") {
+		t.Errorf("created only: %q", got)
+	}
+
+	s.LastActivity = now.Add(-5 * time.Minute)
+	if got := sessionMeta(s, now); !strings.Contains(got, "5m ago") || strings.Contains(got, "up") {
+		t.Errorf("last activity should win: %q", got)
+	}
+
+	s.TaskEndedAt = now
+	s.TaskExitCode = 2
+	if got := sessionMeta(s, now); !strings.Contains(got, "exit 2") {
+		t.Errorf("failed task: %q", got)
+	}
+	s.TaskExitCode = 0
+	if got := sessionMeta(s, now); !strings.Contains(got, "exited") {
+		t.Errorf("finished task: %q", got)
+	}
+
+	s.Process = &procinfo.Info{Command: "cargo build", CPU: 97, RSS: 340 << 20}
+	if got := sessionMeta(s, now); !strings.Contains(got, "cargo build 97% 340M") {
+		t.Errorf("running process: %q", got)
+	}
+}
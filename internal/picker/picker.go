@@ -2,30 +2,36 @@ package picker
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/history"
+	"github.com/nerveband/zpick/internal/shell"
 	"github.com/nerveband/zpick/internal/switcher"
 	"golang.org/x/term"
 )
 
 // ANSI color codes
 const (
-	reset    = "\033[0m"
-	dim      = "\033[2m"
-	red      = "\033[31m"
-	cyan     = "\033[36m"
-	green    = "\033[32m"
-	yellow   = "\033[33m"
-	magenta  = "\033[35m"
-	boldRed  = "\033[1;31m"
-	boldCyan = "\033[1;36m"
-	boldGrn  = "\033[1;32m"
-	boldYel  = "\033[1;33m"
-	boldWht  = "\033[1;97m"
+	reset     = "\033[0m"
+	dim       = "\033[2m"
+	red       = "\033[31m"
+	cyan      = "\033[36m"
+	green     = "\033[32m"
+	yellow    = "\033[33m"
+	magenta   = "\033[35m"
+	boldRed   = "\033[1;31m"
+	boldCyan  = "\033[1;36m"
+	boldGrn   = "\033[1;32m"
+	boldYel   = "\033[1;33m"
+	boldWht   = "\033[1;97m"
+	reverse   = "\033[7m"
+	underline = "\033[4m"
 )
 
 type ActionType int
@@ -38,31 +44,91 @@ const (
 	ActionZoxide
 	ActionKill
 	ActionKillAll
+	ActionKillMarked
+	ActionRename
+	ActionPreview
+	ActionPin
+	ActionProtect
+	ActionFilter
 	ActionHelp
 	ActionEscape
 )
 
 type Action struct {
-	Type ActionType
-	Name string
+	Type    ActionType
+	Name    string
+	Backend string // owning backend of the selected session (aggregated mode)
+	NewName string // for ActionRename
+	Dir     string // where to recreate a pinned session that isn't running
 }
 
+// pickerState is what the picker remembers between redraws.
+type pickerState struct {
+	// previewName and previewBackend identify the session shown in the
+	// preview pane; empty when the pane is closed.
+	previewName    string
+	previewBackend string
+
+	// filter narrows the list to sessions that fuzzy-match it.
+	filter string
+
+	// page is the page of the (filtered) list being shown.
+	page int
+
+	// selected is the highlighted row as an index into the filtered list,
+	// or -1 before the cursor keys have been used.
+	selected int
+
+	// shown is the listing as last drawn, in label order. Sessions that
+	// vanished during a live refresh stay in it, marked in gone, until the
+	// next keypress re-lists.
+	shown []backend.Session
+	gone  map[string]bool
+
+	// order sorts a fresh listing by the configured sort mode.
+	order func([]backend.Session)
+
+	// pins are the pinned sessions, listed first; pinned holds the keys of
+	// their rows, true for a placeholder of one that isn't running.
+	pins   []backend.Pin
+	pinned map[string]bool
+
+	// protected are the sessions bulk kills leave alone.
+	protected []backend.Protected
+
+	// marked holds the keys of the sessions marked in kill mode.
+	marked map[string]bool
+}
+
+// drawMode selects the key hints drawPicker shows under the list.
+type drawMode int
+
+const (
+	drawBrowse drawMode = iota // the picker's own keys
+	drawFilter                 // typing a filter
+	drawMark                   // marking sessions to kill
+)
+
 // Run is the main interactive picker loop.
-// Returns a shell command string to be eval'd by the caller, or empty string.
-func Run(b backend.Backend, version string) (string, error) {
+// Returns the command for the caller's shell to eval, or a zero Command.
+func Run(b backend.Backend, version string) (shell.Command, error) {
 	// Detect in-session mode
 	inSession := b.InSession() && os.Getenv("ZPICK") == ""
 	var currentSession string
 	if inSession {
 		currentSession = b.CurrentSessionName()
 	}
+	currentBackend := b.Name()
+	if agg, ok := b.(*backend.Aggregate); ok {
+		currentBackend = agg.CurrentBackend()
+	}
 
 	// Load key mode preference (letters-first or numbers-first)
 	LoadKeyMode(backend.ReadKeyMode())
 
 	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
-		return "", nil
+		return shell.Command{}, nil
 	}
 	defer tty.Close()
 
@@ -70,51 +136,54 @@ func Run(b backend.Backend, version string) (string, error) {
 	if ok, _ := b.Available(); !ok {
 		fmt.Fprintf(tty, "\n  %szp:%s %s not found\n", boldCyan, reset, b.BinaryName())
 		fmt.Fprintf(tty, "  %sRun 'zp check' for full dependency status%s\n\n", dim, reset)
-		return "", nil
+		return shell.Command{}, nil
 	}
 
+	state := pickerState{selected: -1, order: sessionOrder(b)}
+	state.pins, _ = backend.ReadPins()
+	state.protected, _ = backend.ReadProtected()
 	for {
-		sessions, err := b.FastList()
+		fresh, err := b.FastList()
 		if err != nil {
-			return "", fmt.Errorf("failed to list sessions: %w", err)
+			return shell.Command{}, fmt.Errorf("failed to list sessions: %w", err)
 		}
+		state.shown, _ = arrange(state.shown, state.prepare(b, fresh), false)
+		state.gone = nil
 
-		action, err := showPicker(tty, b, sessions, currentSession)
+		action, err := showPicker(tty, b, state.shown, currentSession, currentBackend, &state)
 		if err != nil {
-			return "", err
+			return shell.Command{}, err
 		}
+		sessions := state.present()
 
 		switch action.Type {
 		case ActionAttach:
 			if inSession {
-				switcher.Write(switcher.Target{Action: "attach", Name: action.Name})
-				return b.DetachCommand(), nil
+				return switchTo(b, action.Backend, switcher.Target{Action: "attach", Name: action.Name, Dir: action.Dir})
 			}
-			return "exec " + b.AttachCommand(action.Name, ""), nil
+			return execAttach(owner(b, action.Backend), action.Name, action.Dir), nil
 		case ActionNew:
 			cwd, _ := os.Getwd()
 			name := CounterName(cwd, sessions)
 			fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, name, reset)
 			if inSession {
-				switcher.Write(switcher.Target{Action: "new", Name: name})
-				return b.DetachCommand(), nil
+				return switchTo(b, "", switcher.Target{Action: "new", Name: name})
 			}
-			return "exec " + b.AttachCommand(name, ""), nil
+			return execAttach(b, name, ""), nil
 		case ActionNewDate:
 			cwd, _ := os.Getwd()
 			name := DateName(cwd)
 			fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, name, reset)
 			if inSession {
-				switcher.Write(switcher.Target{Action: "new", Name: name})
-				return b.DetachCommand(), nil
+				return switchTo(b, "", switcher.Target{Action: "new", Name: name})
 			}
-			return "exec " + b.AttachCommand(name, ""), nil
+			return execAttach(b, name, ""), nil
 		case ActionCustom:
-			cmd, err := handleCustom(tty, b, sessions, inSession)
+			cmd, chosen, err := handleCustom(tty, b, sessions, inSession)
 			if err != nil {
-				return "", err
+				return shell.Command{}, err
 			}
-			if cmd != "" {
+			if chosen {
 				return cmd, nil
 			}
 			continue
@@ -126,33 +195,248 @@ func Run(b backend.Backend, version string) (string, error) {
 			name := CounterName(dir, sessions)
 			fmt.Fprintf(tty, "\n  %s>%s %s%s%s %s%s%s\n\n", boldGrn, reset, boldWht, name, reset, dim, dir, reset)
 			if inSession {
-				switcher.Write(switcher.Target{Action: "new", Name: name, Dir: dir})
-				return b.DetachCommand(), nil
+				return switchTo(b, "", switcher.Target{Action: "new", Name: name, Dir: dir})
 			}
-			return fmt.Sprintf("cd %q && exec %s", dir, b.AttachCommand(name, "")), nil
+			return execAttach(b, name, dir), nil
 		case ActionKill:
 			if action.Name == "" {
 				continue // no session selected, redraw
 			}
-			if err := confirmAndKill(tty, b, action.Name); err != nil {
+			if state.placeholder(backend.Session{Name: action.Name, Backend: action.Backend}) {
+				fmt.Fprintf(tty, "  %s%s isn't running%s\n", dim, action.Name, reset)
+				time.Sleep(1200 * time.Millisecond)
+				continue
+			}
+			target := state.find(action.Name, action.Backend)
+			if state.isProtected(b, target) {
+				fmt.Fprintf(tty, "  %s%s is protected, press ! to unprotect it first%s\n", yellow, action.Name, reset)
+				time.Sleep(1200 * time.Millisecond)
+				continue
+			}
+			if err := confirmAndKill(tty, owner(b, action.Backend), target); err != nil {
 				fmt.Fprintf(tty, "  %sfailed: %v%s\n", dim, err, reset)
 			} else {
 				fmt.Fprintf(tty, "  %skilled%s %s%s%s\n", boldRed, reset, boldWht, action.Name, reset)
 			}
 			continue
 		case ActionKillAll:
-			confirmAndKillAll(tty, b, sessions)
+			if targets := state.spareProtected(tty, b, filterSessions(sessions, state.filter)); len(targets) > 0 {
+				confirmAndKillAll(tty, b, targets)
+			} else {
+				time.Sleep(1200 * time.Millisecond)
+			}
+			continue
+		case ActionKillMarked:
+			var marked []backend.Session
+			for _, s := range sessions {
+				if state.marked[sessionKey(s)] {
+					marked = append(marked, s)
+				}
+			}
+			state.marked = nil
+			if marked = state.spareProtected(tty, b, marked); len(marked) > 0 {
+				confirmAndKillMany(tty, b, marked, fmt.Sprintf("%d marked", len(marked)))
+			}
+			continue
+		case ActionRename:
+			if action.Name == "" || action.NewName == "" {
+				continue // cancelled, redraw
+			}
+			renamed := backend.Session{Name: action.Name, Backend: action.Backend}
+			if state.placeholder(renamed) {
+				fmt.Fprintf(tty, "  %s%s isn't running%s\n", dim, action.Name, reset)
+			} else if err := backend.CheckRename(sessions, action.Name, action.NewName); err != nil {
+				fmt.Fprintf(tty, "  %s%v%s\n", dim, err, reset)
+			} else if err := owner(b, action.Backend).Rename(action.Name, action.NewName); err != nil {
+				fmt.Fprintf(tty, "  %sfailed: %v%s\n", dim, err, reset)
+			} else {
+				fmt.Fprintf(tty, "  %srenamed%s %s%s%s\n", boldCyan, reset, boldWht, action.NewName, reset)
+				if state.previewName == action.Name && state.previewBackend == action.Backend {
+					state.previewName = action.NewName
+				}
+				if _, ok := state.pinned[sessionKey(renamed)]; ok {
+					// The pin follows the session to its new name.
+					backend.RenamePin(owner(b, action.Backend).Name(), action.Name, action.NewName)
+					state.pins, _ = backend.ReadPins()
+				}
+				continue
+			}
+			time.Sleep(1200 * time.Millisecond)
+			continue
+		case ActionPreview:
+			state.previewName, state.previewBackend = action.Name, action.Backend
+			continue
+		case ActionPin:
+			if action.Name == "" {
+				continue // cancelled, redraw
+			}
+			if err := state.togglePin(b, backend.Session{Name: action.Name, Backend: action.Backend}); err != nil {
+				fmt.Fprintf(tty, "  %sfailed: %v%s\n", dim, err, reset)
+				time.Sleep(1200 * time.Millisecond)
+			}
+			// Pinned sessions move to the top: relabel from scratch.
+			state.shown = nil
+			state.page, state.selected = 0, -1
+			continue
+		case ActionProtect:
+			if action.Name == "" {
+				continue // cancelled, redraw
+			}
+			if err := state.toggleProtect(b, backend.Session{Name: action.Name, Backend: action.Backend}); err != nil {
+				fmt.Fprintf(tty, "  %sfailed: %v%s\n", dim, err, reset)
+				time.Sleep(1200 * time.Millisecond)
+			}
+			continue
+		case ActionFilter:
+			state.filter = action.Name
+			state.page, state.selected = 0, -1
 			continue
 		case ActionHelp:
 			showHelpConfig(tty, b, version)
+			// The sort mode may have changed: start over from a fresh order.
+			state.order = sessionOrder(b)
+			state.shown = nil
 			continue
 		case ActionEscape:
-			return "", nil
+			return shell.Command{}, nil
+		}
+	}
+}
+
+func showPicker(tty *os.File, b backend.Backend, sessions []backend.Session, currentSession, currentBackend string, state *pickerState) (Action, error) {
+	visible := filterSessions(sessions, state.filter)
+	state.page = clampPage(state.page, len(visible))
+	state.selected = min(state.selected, len(visible)-1)
+
+	wait := refreshInterval
+	redraw := true
+	for {
+		onPage := pageOf(visible, state.page)
+		if redraw {
+			drawPicker(tty, b, sessions, visible, currentSession, currentBackend, state, drawBrowse)
+			fmt.Fprintf(tty, "  %s>%s ", boldCyan, reset)
+		}
+		redraw = true
+
+		k, ok, err := readKey(tty, wait)
+		if err != nil {
+			fmt.Fprintln(tty)
+			return Action{}, err
+		}
+		if !ok {
+			// No key yet: re-list, and redraw only if something changed.
+			start := time.Now()
+			fresh, err := b.FastList()
+			wait = nextRefresh(time.Since(start))
+			if err != nil {
+				redraw = false
+				continue
+			}
+			pinned := state.pinned
+			next, gone := arrange(sessions, state.prepare(b, fresh), true)
+			if sameListing(next, sessions) && maps.Equal(gone, state.gone) && maps.Equal(pinned, state.pinned) {
+				redraw = false
+				continue
+			}
+			sessions, state.shown, state.gone = next, next, gone
+			visible = filterSessions(sessions, state.filter)
+			state.page = clampPage(state.page, len(visible))
+			state.selected = min(state.selected, len(visible)-1)
+			continue
+		}
+		fmt.Fprintln(tty)
+
+		// Navigation only redraws; it doesn't need a fresh session list.
+		if state.move(k, visible) {
+			continue
+		}
+
+		switch k.kind {
+		case keyEscape:
+			if state.selected >= 0 {
+				state.selected = -1 // drop the highlight first
+				continue
+			}
+			if state.filter != "" {
+				return Action{Type: ActionFilter}, nil // clear the filter
+			}
+			return Action{Type: ActionEscape}, nil
+		case keyEnter:
+			if state.selected >= 0 {
+				s := visible[state.selected]
+				if state.gone[sessionKey(s)] {
+					continue
+				}
+				return state.pick(tty, s), nil
+			}
+			return Action{Type: ActionNew}, nil
+		case keyUnknown:
+			continue
 		}
+
+		switch key := k.r; key {
+		case 'z':
+			return Action{Type: ActionZoxide}, nil
+		case 'd':
+			return Action{Type: ActionNewDate}, nil
+		case 'c':
+			return Action{Type: ActionCustom}, nil
+		case 'x':
+			return enterKillMode(tty, b, sessions, visible, currentSession, currentBackend, state)
+		case 'h':
+			return Action{Type: ActionHelp}, nil
+		case '\t':
+			if state.previewName != "" {
+				return Action{Type: ActionPreview}, nil // close the pane
+			}
+			if state.selected >= 0 {
+				s := visible[state.selected]
+				return Action{Type: ActionPreview, Name: s.Name, Backend: s.Backend}, nil
+			}
+			return enterPreviewMode(tty, onPage)
+		case '+':
+			if state.selected >= 0 {
+				s := visible[state.selected]
+				return Action{Type: ActionPin, Name: s.Name, Backend: s.Backend}, nil
+			}
+			return chooseSession(tty, onPage, "pin", ActionPin)
+		case '-':
+			if s, ok := previous(b, sessions, currentSession, currentBackend); ok {
+				return state.pick(tty, s), nil
+			}
+			fmt.Fprintf(tty, "  %sno previous session%s\n", dim, reset)
+			time.Sleep(1200 * time.Millisecond)
+			continue
+		case '!':
+			if state.selected >= 0 {
+				s := visible[state.selected]
+				return Action{Type: ActionProtect, Name: s.Name, Backend: s.Backend}, nil
+			}
+			return chooseSession(tty, onPage, "protect", ActionProtect)
+		case '/':
+			if len(sessions) > 0 {
+				return enterFilterMode(tty, b, sessions, currentSession, currentBackend, state)
+			}
+		case 'r':
+			if canRename(b, visible) {
+				return enterRenameMode(tty, b, onPage)
+			}
+		default:
+			if idx, ok := IndexForKey(key); ok && idx < len(onPage) {
+				if state.gone[sessionKey(onPage[idx])] {
+					continue // ended while the picker was open
+				}
+				return state.pick(tty, onPage[idx]), nil
+			}
+		}
+
+		return Action{Type: ActionEscape}, nil
 	}
 }
 
-func showPicker(tty *os.File, b backend.Backend, sessions []backend.Session, currentSession string) (Action, error) {
+// drawPicker clears the screen and draws the session list and key hints.
+// visible is sessions narrowed by the filter; mode picks the key hints.
+func drawPicker(tty *os.File, b backend.Backend, sessions, visible []backend.Session, currentSession, currentBackend string, state *pickerState, mode drawMode) {
 	fmt.Fprint(tty, "\033[H\033[2J") // clear screen
 	fmt.Fprintln(tty)
 
@@ -161,32 +445,103 @@ func showPicker(tty *os.File, b backend.Backend, sessions []backend.Session, cur
 		if len(sessions) > 1 {
 			plural = "s"
 		}
+		count := fmt.Sprintf("%d session%s", len(sessions), plural)
+		if state.filter != "" {
+			count = fmt.Sprintf("%d of %s  /%s", len(visible), count, state.filter)
+		}
+		if pages := pageCount(len(visible)); pages > 1 {
+			count += fmt.Sprintf("  page %d/%d", state.page+1, pages)
+		}
 		if currentSession != "" {
-			fmt.Fprintf(tty, "  %s%s%s %s%d session%s%s  %s(in: %s ←)%s\n\n",
-				boldCyan, b.Name(), reset, dim, len(sessions), plural, reset,
+			fmt.Fprintf(tty, "  %s%s%s %s%s%s  %s(in: %s ←)%s\n\n",
+				boldCyan, b.Name(), reset, dim, count, reset,
 				dim, currentSession, reset)
 		} else {
-			fmt.Fprintf(tty, "  %s%s%s %s%d session%s%s\n\n", boldCyan, b.Name(), reset, dim, len(sessions), plural, reset)
+			fmt.Fprintf(tty, "  %s%s%s %s%s%s\n\n", boldCyan, b.Name(), reset, dim, count, reset)
 		}
 
-		for i, s := range sessions {
-			if i >= MaxSessions {
-				break
-			}
+		now := time.Now()
+		previewing := -1
+		if len(visible) == 0 {
+			fmt.Fprintf(tty, "  %sno matches%s\n", dim, reset)
+		}
+		onPage := pageOf(visible, state.page)
+		for i, s := range onPage {
 			indicator := fmt.Sprintf("%s.%s", dim, reset)
-			if s.Name == currentSession {
+			if isCurrent(s, currentSession, currentBackend) {
 				indicator = fmt.Sprintf("%s←%s", boldCyan, reset)
 			} else if s.Active {
 				indicator = fmt.Sprintf("%s*%s", boldGrn, reset)
 			}
-			dir := truncatePath(s.StartedIn, 40)
-			fmt.Fprintf(tty, "  %s%c%s  %s%s%s %s %s%s%s\n",
-				boldYel, KeyForIndex(i), reset,
-				boldWht, s.Name, reset,
+			dir := ""
+			if showsDir(owner(b, s.Backend).Capabilities()) {
+				dir = truncatePath(s.StartedIn, 40)
+			}
+			tag := ""
+			if s.Backend != "" {
+				tag = fmt.Sprintf(" %s[%s]%s", dim, s.Backend, reset)
+			}
+			if state.isProtected(b, s) {
+				tag += fmt.Sprintf(" %s!%s", yellow, reset)
+			}
+			meta := sessionMeta(s, now)
+			if meta != "" && dir != "" {
+				meta = "  " + meta
+			}
+			cursor := "  "
+			if state.page*MaxSessions+i == state.selected {
+				cursor = fmt.Sprintf(" %s›%s", boldCyan, reset)
+			}
+			label, name := boldYel, boldWht
+			if _, ok := state.pinned[sessionKey(s)]; ok {
+				label += underline
+			}
+			if state.marked[sessionKey(s)] {
+				label, name = boldRed+reverse, boldRed
+			}
+			if s.Dead {
+				// Listed, but its server is gone: picking it offers to clean it up.
+				name = dim
+				indicator = fmt.Sprintf("%s✗%s", boldRed, reset)
+				meta = fmt.Sprintf("%sdead%s", red, reset)
+				if dir != "" {
+					meta = "  " + meta
+				}
+			}
+			if state.placeholder(s) {
+				// Pinned but not running: keep its label; picking it recreates it.
+				name = dim
+				indicator = fmt.Sprintf("%s○%s", dim, reset)
+				meta = fmt.Sprintf("%snot running%s", dim, reset)
+				if dir != "" {
+					meta = "  " + meta
+				}
+			}
+			if s.Name == state.previewName && s.Backend == state.previewBackend {
+				label += reverse
+				previewing = i
+			}
+			if state.gone[sessionKey(s)] {
+				// Keep the row so later labels don't shift; it's dropped on the next keypress.
+				label, name = dim, dim
+				indicator = fmt.Sprintf("%s×%s", dim, reset)
+				dir, meta = "", fmt.Sprintf("%sgone%s", dim, reset)
+			}
+			fmt.Fprintf(tty, "%s%s%c%s  %s%s%s%s %s %s%s%s%s\n",
+				cursor, label, KeyForIndex(i), reset,
+				name, s.Name, reset, tag,
 				indicator,
-				dim, dir, reset)
+				dim, dir, reset, meta)
 		}
 		fmt.Fprintln(tty)
+		if previewing >= 0 {
+			s := onPage[previewing]
+			width, _, err := term.GetSize(int(tty.Fd()))
+			if err != nil {
+				width = 80
+			}
+			renderPreview(tty, owner(b, s.Backend), s, width, now)
+		}
 	} else {
 		if currentSession != "" {
 			fmt.Fprintf(tty, "  %s%s%s %sno sessions%s  %s(in: %s ←)%s\n\n",
@@ -197,74 +552,198 @@ func showPicker(tty *os.File, b backend.Backend, sessions []backend.Session, cur
 		}
 	}
 
-	cwd, _ := os.Getwd()
-	defaultName := CounterName(cwd, sessions)
-	fmt.Fprintf(tty, "  %senter%s %snew%s %s%s%s\n", boldGrn, reset, dim, reset, boldWht, defaultName, reset)
-	fmt.Fprintf(tty, "  %sc%s %scustom%s  %sz%s %spick dir%s  %sd%s %s+date%s\n",
+	switch mode {
+	case drawFilter:
+		fmt.Fprintf(tty, "  %stype%s %sto filter%s  %senter%s %sapply%s  %sesc%s %sclear%s\n",
+			boldWht, reset, dim, reset,
+			boldGrn, reset, dim, reset,
+			yellow, reset, dim, reset)
+		fmt.Fprintln(tty)
+		return
+	case drawMark:
+		fmt.Fprintf(tty, "  %skey%s %smark%s  ", boldYel, reset, dim, reset)
+		if state.selected >= 0 {
+			fmt.Fprintf(tty, "%sspace%s %smark highlighted%s  ", boldYel, reset, dim, reset)
+		}
+		fmt.Fprintf(tty, "%senter%s %skill %d%s  %sesc%s %scancel%s\n",
+			boldRed, reset, dim, len(state.marked), reset,
+			yellow, reset, dim, reset)
+		fmt.Fprintln(tty)
+		return
+	}
+
+	if state.selected >= 0 {
+		fmt.Fprintf(tty, "  %senter%s %sattach%s %s%s%s  %s↑↓ jk%s %smove%s\n", boldGrn, reset, dim, reset, boldWht, visible[state.selected].Name, reset, cyan, reset, dim, reset)
+	} else {
+		cwd, _ := os.Getwd()
+		defaultName := CounterName(cwd, sessions)
+		fmt.Fprintf(tty, "  %senter%s %snew%s %s%s%s\n", boldGrn, reset, dim, reset, boldWht, defaultName, reset)
+	}
+	fmt.Fprintf(tty, "  %sc%s %scustom%s  %sz%s %spick dir%s  %sd%s %s+date%s",
 		magenta, reset, dim, reset,
 		magenta, reset, dim, reset,
 		cyan, reset, dim, reset)
-	fmt.Fprintf(tty, "  %sk%s %skill%s  %sh%s %shelp%s  %sesc%s %sskip%s\n",
-		red, reset, dim, reset,
+	if len(sessions) > 0 {
+		fmt.Fprintf(tty, "  %s/%s %sfilter%s", cyan, reset, dim, reset)
+	}
+	fmt.Fprintln(tty)
+	fmt.Fprintf(tty, "  %sx%s %skill%s  ", red, reset, dim, reset)
+	if canRename(b, visible) {
+		fmt.Fprintf(tty, "%sr%s %srename%s  ", cyan, reset, dim, reset)
+	}
+	if len(visible) > 0 {
+		fmt.Fprintf(tty, "%stab%s %spreview%s  %s+%s %spin%s  ", cyan, reset, dim, reset, cyan, reset, dim, reset)
+	}
+	if pageCount(len(visible)) > 1 {
+		fmt.Fprintf(tty, "%s[ ]%s %spage%s  ", cyan, reset, dim, reset)
+	}
+	escHint := "skip"
+	switch {
+	case state.selected >= 0:
+		escHint = "deselect"
+	case state.filter != "":
+		escHint = "clear filter"
+	}
+	fmt.Fprintf(tty, "%sh%s %shelp%s  %sesc%s %s%s%s\n",
 		cyan, reset, dim, reset,
-		yellow, reset, dim, reset)
+		yellow, reset, dim, escHint, reset)
 	fmt.Fprintln(tty)
+}
 
-	fmt.Fprintf(tty, "  %s>%s ", boldCyan, reset)
+// enterFilterMode reads a filter query, redrawing the narrowed list after
+// each keystroke. Enter keeps the filter, Esc clears it.
+func enterFilterMode(tty *os.File, b backend.Backend, sessions []backend.Session, currentSession, currentBackend string, state *pickerState) (Action, error) {
+	view := *state
+	view.page, view.selected = 0, -1
+	buf := make([]byte, 64)
+	for {
+		drawPicker(tty, b, sessions, filterSessions(sessions, view.filter), currentSession, currentBackend, &view, drawFilter)
+		fmt.Fprintf(tty, "  %s/%s %s", boldCyan, reset, view.filter)
+
+		oldState, err := term.MakeRaw(int(tty.Fd()))
+		if err != nil {
+			return Action{}, err
+		}
+		n, err := tty.Read(buf)
+		term.Restore(int(tty.Fd()), oldState)
+		if err != nil {
+			return Action{}, err
+		}
+
+		key := buf[0]
+		switch {
+		case n == 1 && (key == 27 || key == 3): // Escape, Ctrl-C
+			return Action{Type: ActionFilter}, nil
+		case key == 13 || key == 10: // Enter
+			return Action{Type: ActionFilter, Name: view.filter}, nil
+		case key == 127 || key == 8: // Backspace
+			if view.filter != "" {
+				view.filter = view.filter[:len(view.filter)-1]
+			}
+		case key == 27: // arrows and other escape sequences
+		default:
+			for _, c := range buf[:n] {
+				if c >= 32 && c < 127 {
+					view.filter += string(c)
+				}
+			}
+		}
+	}
+}
+
+func enterKillMode(tty *os.File, b backend.Backend, sessions, visible []backend.Session, currentSession, currentBackend string, state *pickerState) (Action, error) {
+	onPage := pageOf(visible, state.page)
+	if len(onPage) == 0 {
+		fmt.Fprintf(tty, "\n  %sno sessions to kill%s\n", dim, reset)
+		time.Sleep(800 * time.Millisecond)
+		return Action{Type: ActionKill}, nil // redraw picker
+	}
+
+	fmt.Fprintf(tty, "\n  %skill%s %swhich session? %sspace%s %smark several%s  %sc%s %sclear all%s ",
+		boldRed, reset, dim, boldRed, reset, dim, reset, boldRed, reset, dim, reset)
 
 	oldState, err := term.MakeRaw(int(tty.Fd()))
 	if err != nil {
-		return Action{}, fmt.Errorf("failed to set raw mode: %w", err)
+		return Action{}, err
 	}
 	defer term.Restore(int(tty.Fd()), oldState)
 
 	buf := make([]byte, 3)
-	n, err := tty.Read(buf)
+	n, _ := tty.Read(buf)
 	term.Restore(int(tty.Fd()), oldState)
 	fmt.Fprintln(tty)
 
-	if err != nil {
-		return Action{}, err
+	if n == 1 && buf[0] == 27 {
+		return Action{Type: ActionKill}, nil // cancelled, redraw picker
 	}
 
-	key := buf[0]
+	if buf[0] == ' ' {
+		return enterMarkMode(tty, b, sessions, visible, currentSession, currentBackend, state)
+	}
 
-	if n == 1 && key == 27 {
-		return Action{Type: ActionEscape}, nil
+	if buf[0] == 'c' || buf[0] == 'C' {
+		return Action{Type: ActionKillAll}, nil
 	}
-	if n == 1 && (key == 13 || key == 10) {
-		return Action{Type: ActionNew}, nil
+
+	if idx, ok := IndexForKey(buf[0]); ok && idx < len(onPage) {
+		return Action{Type: ActionKill, Name: onPage[idx].Name, Backend: onPage[idx].Backend}, nil
 	}
 
-	switch key {
-	case 'z':
-		return Action{Type: ActionZoxide}, nil
-	case 'd':
-		return Action{Type: ActionNewDate}, nil
-	case 'c':
-		return Action{Type: ActionCustom}, nil
-	case 'k':
-		return enterKillMode(tty, sessions)
-	case 'h':
-		return Action{Type: ActionHelp}, nil
-	default:
-		if idx, ok := IndexForKey(key); ok && idx < len(sessions) {
-			fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, sessions[idx].Name, reset)
-			return Action{Type: ActionAttach, Name: sessions[idx].Name}, nil
-		}
+	return Action{Type: ActionKill}, nil // invalid key, redraw picker
+}
+
+func enterPreviewMode(tty *os.File, sessions []backend.Session) (Action, error) {
+	if len(sessions) == 0 {
+		return Action{Type: ActionPreview}, nil
 	}
 
-	return Action{Type: ActionEscape}, nil
+	fmt.Fprintf(tty, "\n  %spreview%s %swhich session?%s ", boldCyan, reset, dim, reset)
+
+	oldState, err := term.MakeRaw(int(tty.Fd()))
+	if err != nil {
+		return Action{}, err
+	}
+	defer term.Restore(int(tty.Fd()), oldState)
+
+	buf := make([]byte, 3)
+	tty.Read(buf)
+	term.Restore(int(tty.Fd()), oldState)
+	fmt.Fprintln(tty)
+
+	if idx, ok := IndexForKey(buf[0]); ok && idx < len(sessions) {
+		return Action{Type: ActionPreview, Name: sessions[idx].Name, Backend: sessions[idx].Backend}, nil
+	}
+	return Action{Type: ActionPreview}, nil // cancelled or invalid key
 }
 
-func enterKillMode(tty *os.File, sessions []backend.Session) (Action, error) {
+// chooseSession asks which session to apply verb to and returns an action
+// of type t for it, or with no name if cancelled.
+func chooseSession(tty *os.File, sessions []backend.Session, verb string, t ActionType) (Action, error) {
 	if len(sessions) == 0 {
-		fmt.Fprintf(tty, "\n  %sno sessions to kill%s\n", dim, reset)
-		time.Sleep(800 * time.Millisecond)
-		return Action{Type: ActionKill}, nil // redraw picker
+		return Action{Type: t}, nil
+	}
+
+	fmt.Fprintf(tty, "\n  %s%s%s %swhich session?%s ", boldCyan, verb, reset, dim, reset)
+
+	oldState, err := term.MakeRaw(int(tty.Fd()))
+	if err != nil {
+		return Action{}, err
+	}
+	defer term.Restore(int(tty.Fd()), oldState)
+
+	buf := make([]byte, 3)
+	tty.Read(buf)
+	term.Restore(int(tty.Fd()), oldState)
+	fmt.Fprintln(tty)
+
+	if idx, ok := IndexForKey(buf[0]); ok && idx < len(sessions) {
+		return Action{Type: t, Name: sessions[idx].Name, Backend: sessions[idx].Backend}, nil
 	}
+	return Action{Type: t}, nil // cancelled or invalid key
+}
 
-	fmt.Fprintf(tty, "\n  %skill%s %swhich session? %sc%s %sclear all%s ", boldRed, reset, dim, boldRed, reset, dim, reset)
+func enterRenameMode(tty *os.File, b backend.Backend, sessions []backend.Session) (Action, error) {
+	fmt.Fprintf(tty, "\n  %srename%s %swhich session?%s ", boldCyan, reset, dim, reset)
 
 	oldState, err := term.MakeRaw(int(tty.Fd()))
 	if err != nil {
@@ -278,21 +757,41 @@ func enterKillMode(tty *os.File, sessions []backend.Session) (Action, error) {
 	fmt.Fprintln(tty)
 
 	if n == 1 && buf[0] == 27 {
-		return Action{Type: ActionKill}, nil // cancelled, redraw picker
+		return Action{Type: ActionRename}, nil // cancelled, redraw picker
 	}
 
-	if buf[0] == 'c' || buf[0] == 'C' {
-		return Action{Type: ActionKillAll}, nil
+	idx, ok := IndexForKey(buf[0])
+	if !ok || idx >= len(sessions) {
+		return Action{Type: ActionRename}, nil // invalid key, redraw picker
 	}
-
-	if idx, ok := IndexForKey(buf[0]); ok && idx < len(sessions) {
-		return Action{Type: ActionKill, Name: sessions[idx].Name}, nil
+	s := sessions[idx]
+	if !owner(b, s.Backend).Capabilities().Rename {
+		fmt.Fprintf(tty, "  %s%s can't rename sessions%s\n", dim, s.Backend, reset)
+		time.Sleep(1200 * time.Millisecond)
+		return Action{Type: ActionRename}, nil
 	}
 
-	return Action{Type: ActionKill}, nil // invalid key, redraw picker
+	fmt.Fprintf(tty, "  %s%s%s %s→%s ", boldWht, s.Name, reset, dim, reset)
+	newName, ok := readLineRaw(tty)
+	if !ok || newName == "" || newName == s.Name {
+		return Action{Type: ActionRename}, nil
+	}
+	return Action{Type: ActionRename, Name: s.Name, Backend: s.Backend, NewName: newName}, nil
 }
 
-func confirmAndKill(tty *os.File, b backend.Backend, name string) error {
+func confirmAndKill(tty *os.File, b backend.Backend, s backend.Session) error {
+	s = withClientCounts(b, []backend.Session{s})[0]
+	name := s.Name
+	if s.Clients != 0 {
+		// Someone may be using it: a y/n is too easy to hit by accident.
+		fmt.Fprintf(tty, "  %s%s has %s attached%s %stype its name to kill:%s ", boldRed, name, clientCount(s), reset, dim, reset)
+		typed, ok := readLineRaw(tty)
+		if ok && typed == name {
+			return b.Kill(name)
+		}
+		fmt.Fprintf(tty, "  %scancelled%s\n", dim, reset)
+		return nil
+	}
 	if os.Getenv("ZPICK_NO_CONFIRM") == "1" {
 		return b.Kill(name)
 	}
@@ -318,39 +817,156 @@ func confirmAndKill(tty *os.File, b backend.Backend, name string) error {
 }
 
 func confirmAndKillAll(tty *os.File, b backend.Backend, sessions []backend.Session) {
-	fmt.Fprintf(tty, "  %skill all %d sessions?%s %sy/n%s ", boldRed, len(sessions), reset, dim, reset)
+	confirmAndKillMany(tty, b, sessions, fmt.Sprintf("all %d", len(sessions)))
+}
 
-	oldState, err := term.MakeRaw(int(tty.Fd()))
-	if err != nil {
-		return
+// confirmAndKillMany asks once, then kills sessions in parallel and
+// reports how each kill went. what describes the set in the prompt. If any
+// of them has clients attached, the answer has to be typed out.
+func confirmAndKillMany(tty *os.File, b backend.Backend, sessions []backend.Session, what string) {
+	attached := 0
+	for _, s := range withClientCounts(b, sessions) {
+		if s.Clients != 0 {
+			attached++
+		}
 	}
-	defer term.Restore(int(tty.Fd()), oldState)
+	if attached > 0 {
+		fmt.Fprintf(tty, "  %skill %s sessions? %d of them have clients attached.%s %stype yes:%s ", boldRed, what, attached, reset, dim, reset)
+		if typed, ok := readLineRaw(tty); !ok || typed != "yes" {
+			fmt.Fprintf(tty, "  %scancelled%s\n", dim, reset)
+			return
+		}
+	} else {
+		fmt.Fprintf(tty, "  %skill %s sessions?%s %sy/n%s ", boldRed, what, reset, dim, reset)
 
-	buf := make([]byte, 1)
-	tty.Read(buf)
-	term.Restore(int(tty.Fd()), oldState)
-	fmt.Fprintln(tty)
+		oldState, err := term.MakeRaw(int(tty.Fd()))
+		if err != nil {
+			return
+		}
+		buf := make([]byte, 1)
+		tty.Read(buf)
+		term.Restore(int(tty.Fd()), oldState)
+		fmt.Fprintln(tty)
 
-	if buf[0] != 'y' && buf[0] != 'Y' {
-		fmt.Fprintf(tty, "  %scancelled%s\n", dim, reset)
-		return
+		if buf[0] != 'y' && buf[0] != 'Y' {
+			fmt.Fprintf(tty, "  %scancelled%s\n", dim, reset)
+			return
+		}
 	}
 
-	for _, s := range sessions {
-		if err := b.Kill(s.Name); err != nil {
-			fmt.Fprintf(tty, "  %sfailed: %s — %v%s\n", dim, s.Name, err, reset)
+	errs := killSessions(b, sessions)
+	failed := 0
+	for i, s := range sessions {
+		if errs[i] != nil {
+			failed++
+			fmt.Fprintf(tty, "  %sfailed: %s — %v%s\n", dim, s.Name, errs[i], reset)
 		} else {
 			fmt.Fprintf(tty, "  %skilled%s %s%s%s\n", boldRed, reset, boldWht, s.Name, reset)
 		}
 	}
+	if failed > 0 {
+		fmt.Fprintf(tty, "  %skilled %d of %d%s\n", dim, len(sessions)-failed, len(sessions), reset)
+		time.Sleep(1200 * time.Millisecond)
+	}
+}
+
+// withClientCounts returns sessions with their client counts re-read from
+// the owning backends' List, since the picker's FastList leaves them at 0
+// for zmx and zmosh. A session whose count can't be read back is given
+// Clients -1, unknown, and treated as attached. Backends that never report
+// client counts are left alone.
+func withClientCounts(b backend.Backend, sessions []backend.Session) []backend.Session {
+	out := append([]backend.Session(nil), sessions...)
+	listed := map[string]map[string]int{} // backend name -> session name -> clients
+	for i, s := range out {
+		m := owner(b, s.Backend)
+		caps := m.Capabilities()
+		if !caps.ClientCounts || !caps.FastList {
+			continue // List has nothing FastList didn't
+		}
+		counts, ok := listed[m.Name()]
+		if !ok {
+			full, err := m.List()
+			if err == nil {
+				counts = make(map[string]int, len(full))
+				for _, f := range full {
+					counts[f.Name] = f.Clients
+				}
+			}
+			listed[m.Name()] = counts
+		}
+		if n, ok := counts[s.Name]; ok {
+			out[i].Clients = n
+		} else {
+			out[i].Clients = -1
+		}
+	}
+	return out
+}
+
+// clientCount describes how many clients s has, e.g. "2" or "clients".
+func clientCount(s backend.Session) string {
+	if s.Clients < 0 {
+		return "clients"
+	}
+	return strconv.Itoa(s.Clients)
+}
+
+// present returns the shown sessions minus those that have gone away and
+// pinned placeholders: the sessions that are actually running.
+func (s *pickerState) present() []backend.Session {
+	if len(s.gone) == 0 && len(s.pinned) == 0 {
+		return s.shown
+	}
+	var sessions []backend.Session
+	for _, sess := range s.shown {
+		if !s.gone[sessionKey(sess)] && !s.placeholder(sess) {
+			sessions = append(sessions, sess)
+		}
+	}
+	return sessions
 }
 
-func handleCustom(tty *os.File, b backend.Backend, sessions []backend.Session, inSession bool) (string, error) {
+// canRename reports whether the picker offers rename mode.
+func canRename(b backend.Backend, visible []backend.Session) bool {
+	return b.Capabilities().Rename && len(visible) > 0
+}
+
+// owner returns the backend that manages a session. In aggregated mode
+// that is the member the session was listed from; otherwise it is b.
+func owner(b backend.Backend, backendName string) backend.Backend {
+	if agg, ok := b.(*backend.Aggregate); ok && backendName != "" {
+		if m := agg.Member(backendName); m != nil {
+			return m
+		}
+	}
+	return b
+}
+
+// isCurrent reports whether s is the session we are running inside.
+func isCurrent(s backend.Session, currentSession, currentBackend string) bool {
+	if currentSession == "" || s.Name != currentSession {
+		return false
+	}
+	return s.Backend == "" || s.Backend == currentBackend
+}
+
+// showsDir reports whether sessions from a backend with caps have a real
+// directory to show. The picker lists with FastList, which only fills in
+// names, so dirs from a backend with a fast list are placeholders.
+func showsDir(caps backend.Capabilities) bool {
+	return caps.SessionDirs && !caps.FastList
+}
+
+// handleCustom asks for a session name and where to start it. chosen is
+// false if the user cancelled; cmd may be zero even when chosen, after a
+// native switch.
+func handleCustom(tty *os.File, b backend.Backend, sessions []backend.Session, inSession bool) (shell.Command, bool, error) {
 	fmt.Fprintf(tty, "\n  %sname:%s ", magenta, reset)
 
 	customName, ok := readLineRaw(tty)
 	if !ok || customName == "" {
-		return "", nil
+		return shell.Command{}, false, nil
 	}
 
 	fmt.Fprintf(tty, "\n  %senter%s %screate in ~%s  %sz%s %spick dir%s  %sesc%s %scancel%s\n\n",
@@ -361,7 +977,7 @@ func handleCustom(tty *os.File, b backend.Backend, sessions []backend.Session, i
 
 	oldState, err := term.MakeRaw(int(tty.Fd()))
 	if err != nil {
-		return "", err
+		return shell.Command{}, false, err
 	}
 	defer term.Restore(int(tty.Fd()), oldState)
 
@@ -375,26 +991,110 @@ func handleCustom(tty *os.File, b backend.Backend, sessions []backend.Session, i
 	if n == 1 && (key == 13 || key == 10) {
 		fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, customName, reset)
 		if inSession {
-			switcher.Write(switcher.Target{Action: "new", Name: customName})
-			return b.DetachCommand(), nil
+			cmd, err := switchTo(b, "", switcher.Target{Action: "new", Name: customName})
+			return cmd, err == nil, err
 		}
-		return "exec " + b.AttachCommand(customName, ""), nil
+		return execAttach(b, customName, ""), true, nil
 	}
 
 	if key == 'z' {
 		dir, err := runZoxide(tty)
 		if err != nil || dir == "" {
-			return "", nil
+			return shell.Command{}, false, nil
 		}
 		fmt.Fprintf(tty, "\n  %s>%s %s%s%s %s%s%s\n\n", boldGrn, reset, boldWht, customName, reset, dim, dir, reset)
 		if inSession {
-			switcher.Write(switcher.Target{Action: "new", Name: customName, Dir: dir})
-			return b.DetachCommand(), nil
+			cmd, err := switchTo(b, "", switcher.Target{Action: "new", Name: customName, Dir: dir})
+			return cmd, err == nil, err
 		}
-		return fmt.Sprintf("cd %q && exec %s", dir, b.AttachCommand(customName, "")), nil
+		return execAttach(b, customName, dir), true, nil
+	}
+
+	return shell.Command{}, false, nil
+}
+
+// sessionOrder returns the configured sort for b's listings.
+func sessionOrder(b backend.Backend) func([]backend.Session) {
+	mode := backend.ReadSortMode()
+	var lastUsed func(backend.Session) time.Time
+	if mode == backend.SortRecent {
+		lastUsed = history.Recency(b.Name())
+	}
+	return func(sessions []backend.Session) {
+		backend.SortSessions(sessions, mode, lastUsed)
 	}
+}
+
+// execAttach returns the command that replaces the shell with a client
+// attached to name, created in dir if it doesn't exist yet, and records
+// the attach in the history.
+func execAttach(b backend.Backend, name, dir string) shell.Command {
+	history.Record(name, backend.OwnerOf(b, name).Name())
+	cmd := backend.AttachIn(b, name, dir)
+	cmd.Exec = true
+	return cmd
+}
 
-	return "", nil
+// switchTo moves the current client to t's session, owned by the member
+// named backendName when aggregated. Backends that can switch natively do
+// it in place and nothing is left to run. Otherwise t is left for the shell
+// the client was started from and the returned command detaches the client,
+// so the shell's hook resumes into t, in whichever backend owns it.
+func switchTo(b backend.Backend, backendName string, t switcher.Target) (shell.Command, error) {
+	dest := backend.OwnerOf(owner(b, backendName), t.Name)
+	if switchNative(dest, t) {
+		return shell.Command{}, nil
+	}
+	return detachTo(b, dest, t)
+}
+
+// Switch moves the current client to the session name, owned by the member
+// named backendName when aggregated, the way the picker does. It runs the
+// detach itself, for callers whose output isn't eval'd by the shell hook.
+func Switch(b backend.Backend, backendName, name string) error {
+	cmd, err := switchTo(b, backendName, switcher.Target{Action: "attach", Name: name})
+	if err != nil || cmd.IsZero() {
+		return err
+	}
+	c := exec.Command(cmd.Args[0], cmd.Args[1:]...)
+	c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	return c.Run()
+}
+
+// switchNative switches in place if dest, the backend owning t's session,
+// is the one we're in and can, and records the switch in the history.
+func switchNative(dest backend.Backend, t switcher.Target) bool {
+	ns, ok := dest.(backend.NativeSwitcher)
+	if !ok || !dest.InSession() || !dest.Capabilities().NativeSwitch {
+		return false
+	}
+	if ns.SwitchTo(t.Name, t.Dir) != nil {
+		return false
+	}
+	history.Record(t.Name, dest.Name())
+	return true
+}
+
+// detachTo leaves t for the shell, naming dest so zp resume attaches
+// through it even when it isn't the backend we're detaching from, and
+// returns the command that detaches the client. The target is keyed by the
+// client's tty when the backend reports it, so only that terminal's shell
+// picks it up. Nothing is written if b can't detach its client, since no
+// shell would resume into the target until some later prompt.
+func detachTo(b, dest backend.Backend, t switcher.Target) (shell.Command, error) {
+	detach := b.DetachCommand()
+	if detach.IsZero() {
+		return shell.Command{}, backend.Unsupported(b, "detach")
+	}
+	t.Backend = dest.Name()
+	var tty string
+	if cl, ok := b.(backend.ClientLocator); ok {
+		tty = cl.ClientTTY()
+	}
+	if err := switcher.Write(switcher.WriteKey(tty), t); err != nil {
+		return shell.Command{}, fmt.Errorf("saving switch target: %w", err)
+	}
+	return detach, nil
 }
 
 // readLineRaw reads a line in raw mode, supporting escape to cancel and backspace.
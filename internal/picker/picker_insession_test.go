@@ -1,11 +1,14 @@
 package picker
 
 import (
+	"errors"
 	"os"
-	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/history"
+	"github.com/nerveband/zpick/internal/shell"
 	"github.com/nerveband/zpick/internal/switcher"
 )
 
@@ -18,25 +21,35 @@ type mockBackend struct {
 	available     bool
 	sessions      []backend.Session
 	detachCmd     string
+	killErr       error // returned by Kill for the session named failKill
+	failKill      string
 }
 
 func (m *mockBackend) Name() string                        { return m.name }
 func (m *mockBackend) BinaryName() string                  { return m.binaryName }
 func (m *mockBackend) SessionEnvVar() string               { return m.sessionEnvVar }
 func (m *mockBackend) InSession() bool                     { return m.inSession }
+func (m *mockBackend) CurrentSessionName() string          { return "" }
 func (m *mockBackend) Available() (bool, error)            { return m.available, nil }
 func (m *mockBackend) Version() (string, error)            { return "1.0.0", nil }
+func (m *mockBackend) Capabilities() backend.Capabilities  { return backend.Capabilities{} }
 func (m *mockBackend) List() ([]backend.Session, error)    { return m.sessions, nil }
 func (m *mockBackend) FastList() ([]backend.Session, error) { return m.sessions, nil }
 func (m *mockBackend) Attach(name string) error            { return nil }
-func (m *mockBackend) AttachCommand(name, dir string) string {
-	if dir != "" {
-		return m.binaryName + " attach " + name + " --dir " + dir
+func (m *mockBackend) AttachCommand(name, dir string) shell.Command {
+	return shell.Command{Args: []string{m.binaryName, "attach", name}, Dir: dir}
+}
+func (m *mockBackend) DetachCommand() shell.Command {
+	return shell.Command{Args: strings.Fields(m.detachCmd)}
+}
+func (m *mockBackend) Kill(name string) error {
+	if name == m.failKill {
+		return m.killErr
 	}
-	return m.binaryName + " attach " + name
+	return nil
 }
-func (m *mockBackend) DetachCommand() string { return m.detachCmd }
-func (m *mockBackend) Kill(name string) error { return nil }
+func (m *mockBackend) Rename(oldName, newName string) error { return nil }
+func (m *mockBackend) Capture(name string, lines int) (string, error) { return "", nil }
 
 func TestInSessionDetection(t *testing.T) {
 	b := &mockBackend{
@@ -63,11 +76,9 @@ func TestInSessionDetection(t *testing.T) {
 }
 
 func TestInSessionAttachWritesSwitchTarget(t *testing.T) {
-	// Set up a temp path for the switch target
-	tmpDir := t.TempDir()
-	switchPath := filepath.Join(tmpDir, "switch-target")
-	switcher.SetPath(switchPath)
-	defer switcher.SetPath("")
+	// Set up a temp dir for the switch targets
+	switcher.SetDir(t.TempDir())
+	defer switcher.SetDir("")
 
 	b := &mockBackend{
 		name:          "tmux",
@@ -85,16 +96,16 @@ func TestInSessionAttachWritesSwitchTarget(t *testing.T) {
 	actionName := "dev"
 
 	if inSession {
-		switcher.Write(switcher.Target{Action: "attach", Name: actionName})
+		switcher.Write(switcher.DefaultKey, switcher.Target{Action: "attach", Name: actionName})
 		cmd := b.DetachCommand()
 
 		// Verify the command is the detach command, not the attach command
-		if cmd != "tmux detach-client" {
+		if cmd.String() != "tmux detach-client" {
 			t.Errorf("expected detach command, got %q", cmd)
 		}
 
 		// Verify the switch target was written
-		target, err := switcher.Read()
+		target, err := switcher.Read(switcher.DefaultKey)
 		if err != nil {
 			t.Fatalf("failed to read switch target: %v", err)
 		}
@@ -108,10 +119,8 @@ func TestInSessionAttachWritesSwitchTarget(t *testing.T) {
 }
 
 func TestInSessionNewWritesSwitchTarget(t *testing.T) {
-	tmpDir := t.TempDir()
-	switchPath := filepath.Join(tmpDir, "switch-target")
-	switcher.SetPath(switchPath)
-	defer switcher.SetPath("")
+	switcher.SetDir(t.TempDir())
+	defer switcher.SetDir("")
 
 	b := &mockBackend{
 		name:          "tmux",
@@ -121,14 +130,14 @@ func TestInSessionNewWritesSwitchTarget(t *testing.T) {
 
 	// Simulate the new action when inSession is true
 	name := "my-project"
-	switcher.Write(switcher.Target{Action: "new", Name: name})
+	switcher.Write(switcher.DefaultKey, switcher.Target{Action: "new", Name: name})
 	cmd := b.DetachCommand()
 
-	if cmd != "tmux detach-client" {
+	if cmd.String() != "tmux detach-client" {
 		t.Errorf("expected detach command, got %q", cmd)
 	}
 
-	target, err := switcher.Read()
+	target, err := switcher.Read(switcher.DefaultKey)
 	if err != nil {
 		t.Fatalf("failed to read switch target: %v", err)
 	}
@@ -141,10 +150,8 @@ func TestInSessionNewWritesSwitchTarget(t *testing.T) {
 }
 
 func TestInSessionZoxideWritesSwitchTarget(t *testing.T) {
-	tmpDir := t.TempDir()
-	switchPath := filepath.Join(tmpDir, "switch-target")
-	switcher.SetPath(switchPath)
-	defer switcher.SetPath("")
+	switcher.SetDir(t.TempDir())
+	defer switcher.SetDir("")
 
 	b := &mockBackend{
 		name:      "tmux",
@@ -155,14 +162,14 @@ func TestInSessionZoxideWritesSwitchTarget(t *testing.T) {
 	// Simulate the zoxide action when inSession is true
 	name := "my-project"
 	dir := "/home/user/projects/my-project"
-	switcher.Write(switcher.Target{Action: "new", Name: name, Dir: dir})
+	switcher.Write(switcher.DefaultKey, switcher.Target{Action: "new", Name: name, Dir: dir})
 	cmd := b.DetachCommand()
 
-	if cmd != "tmux detach-client" {
+	if cmd.String() != "tmux detach-client" {
 		t.Errorf("expected detach command, got %q", cmd)
 	}
 
-	target, err := switcher.Read()
+	target, err := switcher.Read(switcher.DefaultKey)
 	if err != nil {
 		t.Fatalf("failed to read switch target: %v", err)
 	}
@@ -177,7 +184,138 @@ func TestInSessionZoxideWritesSwitchTarget(t *testing.T) {
 	}
 }
 
+// clientBackend is a mockBackend that knows its client's tty.
+type clientBackend struct {
+	mockBackend
+	tty string
+}
+
+func (c *clientBackend) ClientTTY() string { return c.tty }
+
+func TestSwitchToKeysTargetByClient(t *testing.T) {
+	switcher.SetDir(t.TempDir())
+	defer switcher.SetDir("")
+	// The session was started from pts/4, which the shell inside it
+	// inherited; the client attached now runs in pts/9.
+	t.Setenv("ZPICK_CLIENT", "pts-4")
+
+	b := &clientBackend{mockBackend{name: "tmux", detachCmd: "tmux detach-client"}, "/dev/pts/9"}
+	if cmd, err := switchTo(b, "", switcher.Target{Action: "attach", Name: "dev"}); err != nil || cmd.String() != "tmux detach-client" {
+		t.Errorf("expected detach command, got %q, %v", cmd, err)
+	}
+	if _, err := switcher.Read(switcher.ResumeKeys("/dev/pts/4")...); err == nil {
+		t.Error("another terminal's shell took the target")
+	}
+	if target, err := switcher.Read(switcher.ResumeKeys("/dev/pts/9")...); err != nil || target.Name != "dev" {
+		t.Errorf("Read = %+v, %v; want the dev target", target, err)
+	}
+
+	// Without a client tty the target goes to the default key, not to the
+	// terminal the session was started from.
+	if _, err := switchTo(&b.mockBackend, "", switcher.Target{Action: "attach", Name: "api"}); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := switcher.Read(switcher.DefaultKey); err != nil || target.Name != "api" {
+		t.Errorf("Read = %+v, %v; want the api target", target, err)
+	}
+}
+
+func TestSwitchToWithoutDetach(t *testing.T) {
+	switcher.SetDir(t.TempDir())
+	defer switcher.SetDir("")
+
+	// A backend that can't detach its client must not leave a target for
+	// some later prompt to resume into.
+	b := &mockBackend{name: "shpool"}
+	if _, err := switchTo(b, "", switcher.Target{Action: "attach", Name: "dev"}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("switchTo err = %v, want ErrUnsupported", err)
+	}
+	if _, err := switcher.Read(switcher.DefaultKey); err == nil {
+		t.Error("switchTo left a target without detaching")
+	}
+
+	// A target that can't be saved is reported rather than detaching into
+	// a shell with nothing to resume.
+	switcher.SetDir("/dev/null/switch")
+	b.detachCmd = "tmux detach-client"
+	if cmd, err := switchTo(b, "", switcher.Target{Action: "attach", Name: "dev"}); err == nil {
+		t.Errorf("switchTo = %q, want an error for the unwritable target", cmd)
+	}
+}
+
+// switchingBackend is a mockBackend that switches its client in place.
+type switchingBackend struct {
+	mockBackend
+	switchErr error
+	switched  []string
+}
+
+func (s *switchingBackend) Capabilities() backend.Capabilities {
+	return backend.Capabilities{NativeSwitch: true}
+}
+
+func (s *switchingBackend) SwitchTo(name, dir string) error {
+	if s.switchErr != nil {
+		return s.switchErr
+	}
+	s.switched = append(s.switched, name+" "+dir)
+	return nil
+}
+
+func TestSwitchToNative(t *testing.T) {
+	switcher.SetDir(t.TempDir())
+	defer switcher.SetDir("")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	b := &switchingBackend{mockBackend: mockBackend{name: "tmux", inSession: true, detachCmd: "tmux detach-client"}}
+	if cmd, err := switchTo(b, "", switcher.Target{Action: "new", Name: "api", Dir: "/src/api"}); err != nil || !cmd.IsZero() {
+		t.Errorf("native switch returned %q, %v; want nothing to run", cmd, err)
+	}
+	if len(b.switched) != 1 || b.switched[0] != "api /src/api" {
+		t.Errorf("switched = %q, want api in /src/api", b.switched)
+	}
+	if _, err := switcher.Read(switcher.DefaultKey); err == nil {
+		t.Error("native switch left a switch target")
+	}
+	if entries, _ := history.Read(); len(entries) != 1 || entries[0].Name != "api" {
+		t.Errorf("history = %+v, want the switch to api", entries)
+	}
+
+	// A failed switch falls back to detaching.
+	b.switchErr = errors.ErrUnsupported
+	if cmd, err := switchTo(b, "", switcher.Target{Action: "attach", Name: "web"}); err != nil || cmd.String() != "tmux detach-client" {
+		t.Errorf("expected detach command, got %q, %v", cmd, err)
+	}
+	if target, err := switcher.Read(switcher.DefaultKey); err != nil || target.Name != "web" {
+		t.Errorf("Read = %+v, %v; want the web target", target, err)
+	}
+}
+
+func TestSwitchToOtherBackend(t *testing.T) {
+	switcher.SetDir(t.TempDir())
+	defer switcher.SetDir("")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	in := &switchingBackend{mockBackend: mockBackend{name: "tmux", inSession: true, detachCmd: "tmux detach-client"}}
+	other := &mockBackend{name: "zellij", detachCmd: "zellij action detach", sessions: []backend.Session{{Name: "web"}}}
+	agg := backend.NewAggregate([]backend.Backend{in, other})
+
+	// tmux can't switch its client into a zellij session, so it detaches
+	// and leaves a target naming zellij for zp resume.
+	if cmd, err := switchTo(agg, "zellij", switcher.Target{Action: "attach", Name: "web"}); err != nil || cmd.String() != "tmux detach-client" {
+		t.Errorf("expected tmux's detach command, got %q, %v", cmd, err)
+	}
+	if len(in.switched) != 0 {
+		t.Errorf("tmux switched natively to %q", in.switched)
+	}
+	target, err := switcher.Read(switcher.DefaultKey)
+	if err != nil || target.Name != "web" || target.Backend != "zellij" {
+		t.Errorf("Read = %+v, %v; want web in zellij", target, err)
+	}
+}
+
 func TestNotInSessionReturnsAttachCommand(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 	b := &mockBackend{
 		name:       "tmux",
 		binaryName: "tmux",
@@ -189,17 +327,22 @@ func TestNotInSessionReturnsAttachCommand(t *testing.T) {
 	inSession := false
 	actionName := "dev"
 
-	var cmd string
+	var cmd shell.Command
 	if inSession {
 		cmd = b.DetachCommand()
 	} else {
-		cmd = "exec " + b.AttachCommand(actionName, "")
+		cmd = execAttach(b, actionName, "")
 	}
 
 	expected := "exec tmux attach dev"
-	if cmd != expected {
+	if cmd.String() != expected {
 		t.Errorf("expected %q, got %q", expected, cmd)
 	}
+
+	entries, _ := history.Read()
+	if len(entries) != 1 || entries[0].Name != "dev" || entries[0].Backend != "tmux" {
+		t.Errorf("history = %+v, want the attach to tmux/dev", entries)
+	}
 }
 
 func TestCurrentSessionFromEnv(t *testing.T) {
@@ -228,5 +371,5 @@ func TestShowPickerAcceptsCurrentSession(t *testing.T) {
 	// This test just verifies the function signature compiles.
 	// The actual showPicker function reads from /dev/tty so we can't
 	// fully test it in CI, but we verify it has the right signature.
-	var _ func(*os.File, backend.Backend, []backend.Session, string) (Action, error) = showPicker
+	var _ func(*os.File, backend.Backend, []backend.Session, string, string, *pickerState) (Action, error) = showPicker
 }
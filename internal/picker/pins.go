@@ -0,0 +1,108 @@
+package picker
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// pinFirst moves the pinned sessions to the front of sessions, in pin
+// order, so they always get the first labels. A pin that isn't running
+// gets a placeholder row; selecting it recreates the session. The returned
+// map holds the keys of the pinned rows, true for placeholders.
+func pinFirst(b backend.Backend, sessions []backend.Session, pins []backend.Pin) ([]backend.Session, map[string]bool) {
+	if len(pins) == 0 {
+		return sessions, nil
+	}
+	agg, aggregated := b.(*backend.Aggregate)
+	pinned := map[string]bool{}
+	taken := make([]bool, len(sessions))
+	var list []backend.Session
+	for _, p := range pins {
+		if !aggregated && p.Backend != "" && p.Backend != b.Name() {
+			continue // pinned in a backend this picker doesn't show
+		}
+		found := false
+		for i, s := range sessions {
+			if !taken[i] && p.Matches(s, b.Name()) {
+				taken[i], found = true, true
+				list = append(list, s)
+				pinned[sessionKey(s)] = false
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		placeholder := backend.Session{Name: p.Name, StartedIn: p.Dir}
+		if aggregated {
+			placeholder.Backend = p.Backend
+			if placeholder.Backend == "" {
+				placeholder.Backend = backend.OwnerOf(b, p.Name).Name()
+			}
+			if agg.Member(placeholder.Backend) == nil {
+				continue
+			}
+		}
+		if _, dup := pinned[sessionKey(placeholder)]; dup {
+			continue
+		}
+		list = append(list, placeholder)
+		pinned[sessionKey(placeholder)] = true
+	}
+	for i, s := range sessions {
+		if !taken[i] {
+			list = append(list, s)
+		}
+	}
+	return list, pinned
+}
+
+// prepare sorts a fresh listing and puts the pinned sessions first.
+func (s *pickerState) prepare(b backend.Backend, fresh []backend.Session) []backend.Session {
+	backend.AddProcessInfo(fresh)
+	s.order(fresh)
+	list, pinned := pinFirst(b, fresh, s.pins)
+	s.pinned = pinned
+	return list
+}
+
+// placeholder reports whether sess is a pinned session that isn't running.
+func (s *pickerState) placeholder(sess backend.Session) bool {
+	return s.pinned[sessionKey(sess)]
+}
+
+// attachAction returns the action that attaches to sess. A pinned session
+// that isn't running is recreated in its directory.
+func (s *pickerState) attachAction(sess backend.Session) Action {
+	a := Action{Type: ActionAttach, Name: sess.Name, Backend: sess.Backend}
+	if s.placeholder(sess) {
+		a.Dir = sess.StartedIn
+	}
+	return a
+}
+
+// pick returns the action for choosing sess from the list. A dead session
+// can't be attached, so choosing it offers to clean it up instead.
+func (s *pickerState) pick(w io.Writer, sess backend.Session) Action {
+	if sess.Dead {
+		fmt.Fprintf(w, "\n  %s%s is dead%s %s(its server is gone)%s\n", boldRed, sess.Name, reset, dim, reset)
+		return Action{Type: ActionKill, Name: sess.Name, Backend: sess.Backend}
+	}
+	fmt.Fprintf(w, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, sess.Name, reset)
+	return s.attachAction(sess)
+}
+
+// togglePin pins sess, or unpins it if it is pinned, and reloads the pins.
+func (s *pickerState) togglePin(b backend.Backend, sess backend.Session) error {
+	o := owner(b, sess.Backend)
+	var err error
+	if _, ok := s.pinned[sessionKey(sess)]; ok {
+		_, err = backend.RemovePin(sess.Name, o.Name())
+	} else {
+		err = backend.AddPin(backend.NewPin(o, sess.Name))
+	}
+	s.pins, _ = backend.ReadPins()
+	return err
+}
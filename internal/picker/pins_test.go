@@ -0,0 +1,66 @@
+package picker
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestPinFirst(t *testing.T) {
+	b := &mockBackend{name: "tmux"}
+	pins := []backend.Pin{
+		{Name: "notes", Backend: "tmux", Dir: "/notes"},
+		{Name: "api", Backend: "tmux"},
+		{Name: "elsewhere", Backend: "zellij"},
+	}
+
+	list, pinned := pinFirst(b, listing("web", "api", "db"), pins)
+	if got, want := sessionNames(list), []string{"notes", "api", "web", "db"}; !slices.Equal(got, want) {
+		t.Fatalf("pinFirst() = %v, want %v", got, want)
+	}
+	if list[0].StartedIn != "/notes" {
+		t.Errorf("placeholder dir = %q, want /notes", list[0].StartedIn)
+	}
+	if !pinned[sessionKey(list[0])] {
+		t.Error("notes isn't running and should be a placeholder")
+	}
+	if placeholder, ok := pinned[sessionKey(list[1])]; !ok || placeholder {
+		t.Errorf("api should be pinned and running, got %v, %v", placeholder, ok)
+	}
+	if _, ok := pinned[sessionKey(list[2])]; ok {
+		t.Error("web isn't pinned")
+	}
+
+	if list, pinned := pinFirst(b, listing("web"), nil); len(list) != 1 || pinned != nil {
+		t.Errorf("pinFirst() without pins = %v, %v", list, pinned)
+	}
+}
+
+func TestPlaceholderAttachRecreates(t *testing.T) {
+	state := pickerState{pins: []backend.Pin{{Name: "notes", Dir: "/notes"}}, order: func([]backend.Session) {}}
+	list := state.prepare(&mockBackend{name: "tmux"}, listing("web"))
+
+	if a := state.attachAction(list[0]); a.Name != "notes" || a.Dir != "/notes" {
+		t.Errorf("attachAction(placeholder) = %+v, want notes in /notes", a)
+	}
+	if a := state.attachAction(list[1]); a.Name != "web" || a.Dir != "" {
+		t.Errorf("attachAction(web) = %+v", a)
+	}
+
+	state.shown = list
+	if got := sessionNames(state.present()); !slices.Equal(got, []string{"web"}) {
+		t.Errorf("present() = %v, want only the running session", got)
+	}
+}
+
+func TestPickDeadSessionOffersCleanup(t *testing.T) {
+	var state pickerState
+	if a := state.pick(io.Discard, backend.Session{Name: "ghost", Dead: true}); a.Type != ActionKill || a.Name != "ghost" {
+		t.Errorf("pick(dead) = %+v, want a kill of ghost", a)
+	}
+	if a := state.pick(io.Discard, backend.Session{Name: "web"}); a.Type != ActionAttach {
+		t.Errorf("pick(web) = %+v, want an attach", a)
+	}
+}
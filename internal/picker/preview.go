@@ -0,0 +1,127 @@
+package picker
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// previewLines is how much of a session's screen the preview pane shows.
+const previewLines = 10
+
+// captureTimeout bounds how long drawing waits for a capture, which for a
+// remote session is a round trip over ssh.
+var captureTimeout = 2 * time.Second
+
+// ansiRe matches CSI and OSC escape sequences, which plugins and custom
+// capture commands may leave in their output.
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+// renderPreview draws the preview pane for s: its metadata, then the last
+// lines of its screen when the owning backend can capture it.
+func renderPreview(w io.Writer, b backend.Backend, s backend.Session, width int, now time.Time) {
+	fmt.Fprintf(w, "  %s── %s%s%s%s ──%s\n", dim, reset, boldWht, s.Name, dim, reset)
+	if details := previewDetails(s, b.Capabilities(), now); details != "" {
+		fmt.Fprintf(w, "  %s%s%s\n", dim, details, reset)
+	}
+	if !b.Capabilities().Capture {
+		fmt.Fprintln(w)
+		return
+	}
+	screen, err := captureWithin(b, s.Name, captureTimeout)
+	if err != nil {
+		fmt.Fprintf(w, "  %sno preview: %v%s\n\n", dim, err, reset)
+		return
+	}
+	for _, line := range previewText(screen, width-4) {
+		fmt.Fprintf(w, "  %s│%s %s\n", dim, reset, line)
+	}
+	fmt.Fprintln(w)
+}
+
+// captureWithin captures the session's screen, giving up after timeout.
+// A capture that is given up on finishes in the background.
+func captureWithin(b backend.Backend, name string, timeout time.Duration) (string, error) {
+	type result struct {
+		screen string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		screen, err := b.Capture(name, previewLines)
+		done <- result{screen, err}
+	}()
+	select {
+	case r := <-done:
+		return r.screen, r.err
+	case <-time.After(timeout):
+		return "", fmt.Errorf("capture timed out")
+	}
+}
+
+// previewDetails summarizes what the backend knows about s on one line.
+func previewDetails(s backend.Session, caps backend.Capabilities, now time.Time) string {
+	var parts []string
+	if caps.SessionDirs && s.StartedIn != "" {
+		parts = append(parts, truncatePath(s.StartedIn, 40))
+	}
+	if caps.ClientCounts {
+		plural := "s"
+		if s.Clients == 1 {
+			plural = ""
+		}
+		parts = append(parts, fmt.Sprintf("%d client%s", s.Clients, plural))
+	}
+	if caps.PIDs && s.PID != 0 {
+		parts = append(parts, fmt.Sprintf("pid %d", s.PID))
+	}
+	if s.Process != nil {
+		parts = append(parts, s.Process.Summary())
+	}
+	if !s.CreatedAt.IsZero() {
+		parts = append(parts, "up "+relativeAge(s.CreatedAt, now))
+	}
+	if !s.LastActivity.IsZero() {
+		parts = append(parts, "active "+relativeAge(s.LastActivity, now)+" ago")
+	}
+	if s.TaskEnded() {
+		parts = append(parts, fmt.Sprintf("exited %d", s.TaskExitCode))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// previewText makes captured screen output safe to draw inside the picker:
+// escape sequences and control characters are dropped, tabs expanded and
+// each line cut to width.
+func previewText(screen string, width int) []string {
+	screen = ansiRe.ReplaceAllString(screen, "")
+	if strings.TrimSpace(screen) == "" {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(screen, "\n") {
+		var sb strings.Builder
+		n := 0
+		for _, r := range line {
+			if n >= width {
+				break
+			}
+			switch {
+			case r == '\t':
+				sb.WriteString("    ")
+				n += 4
+			case r < 32 || r == 127:
+				continue
+			default:
+				sb.WriteRune(r)
+				n++
+			}
+		}
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
@@ -0,0 +1,63 @@
+package picker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestPreviewText(t *testing.T) {
+	got := previewText("\x1b[32mok\x1b[0m done\r\n\ta\x07b\n"+strings.Repeat("x", 30), 10)
+	want := []string{"ok done", "    ab", "xxxxxxxxxx"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("previewText() = %q, want %q", got, want)
+	}
+	if got := previewText("\n \n", 10); got != nil {
+		t.Errorf("previewText(blank) = %q, want nil", got)
+	}
+}
+
+func TestPreviewDetails(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := backend.Session{
+		Name:      "api",
+		PID:       42,
+		Clients:   1,
+		StartedIn: "/srv/api",
+		CreatedAt: now.Add(-2 * time.Hour),
+	}
+	caps := backend.Capabilities{SessionDirs: true, ClientCounts: true, PIDs: true}
+	if got := previewDetails(s, caps, now); got != "/srv/api · 1 client · pid 42 · up 2h" {
+		t.Errorf("previewDetails() = %q", got)
+	}
+	if got := previewDetails(s, backend.Capabilities{}, now); got != "up 2h" {
+		t.Errorf("previewDetails() without caps = %q", got)
+	}
+}
+
+// slowCapture is a mockBackend whose capture takes delay.
+type slowCapture struct {
+	mockBackend
+	delay time.Duration
+}
+
+func (s *slowCapture) Capture(name string, lines int) (string, error) {
+	time.Sleep(s.delay)
+	return "done", nil
+}
+
+func TestCaptureWithin(t *testing.T) {
+	if screen, err := captureWithin(&slowCapture{delay: 0}, "api", time.Second); err != nil || screen != "done" {
+		t.Errorf("captureWithin() = %q, %v; want the screen", screen, err)
+	}
+	start := time.Now()
+	if _, err := captureWithin(&slowCapture{delay: time.Second}, "api", 20*time.Millisecond); err == nil {
+		t.Error("captureWithin() of a slow capture should time out")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("captureWithin() waited %v", elapsed)
+	}
+}
@@ -0,0 +1,57 @@
+package picker
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// isProtected reports whether sess is on the protected list.
+func (s *pickerState) isProtected(b backend.Backend, sess backend.Session) bool {
+	return backend.IsProtected(s.protected, sess, owner(b, sess.Backend).Name())
+}
+
+// toggleProtect protects sess, or unprotects it if it is protected, and
+// reloads the list.
+func (s *pickerState) toggleProtect(b backend.Backend, sess backend.Session) error {
+	o := owner(b, sess.Backend)
+	var err error
+	if s.isProtected(b, sess) {
+		_, err = backend.Unprotect(sess.Name, o.Name())
+	} else {
+		err = backend.Protect(sess.Name, o.Name())
+	}
+	s.protected, _ = backend.ReadProtected()
+	return err
+}
+
+// spareProtected drops the protected sessions from a set about to be
+// killed, saying which were kept.
+func (s *pickerState) spareProtected(w io.Writer, b backend.Backend, sessions []backend.Session) []backend.Session {
+	var kill []backend.Session
+	var kept []string
+	for _, sess := range sessions {
+		if s.isProtected(b, sess) {
+			kept = append(kept, sess.Name)
+		} else {
+			kill = append(kill, sess)
+		}
+	}
+	if len(kept) > 0 {
+		fmt.Fprintf(w, "  %skeeping protected: %s%s\n", yellow, strings.Join(kept, ", "), reset)
+	}
+	return kill
+}
+
+// find returns the listed session with name and backendName, or a bare
+// session with just those if it isn't listed.
+func (s *pickerState) find(name, backendName string) backend.Session {
+	for _, sess := range s.shown {
+		if sess.Name == name && sess.Backend == backendName {
+			return sess
+		}
+	}
+	return backend.Session{Name: name, Backend: backendName}
+}
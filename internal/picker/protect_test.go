@@ -0,0 +1,67 @@
+package picker
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestSpareProtected(t *testing.T) {
+	b := &mockBackend{name: "tmux"}
+	state := pickerState{protected: []backend.Protected{{Name: "train", Backend: "tmux"}, {Name: "db", Backend: "zellij"}}}
+
+	kill := state.spareProtected(io.Discard, b, listing("api", "train", "db"))
+	if got := sessionNames(kill); !slices.Equal(got, []string{"api", "db"}) {
+		t.Errorf("spareProtected() = %v, want api and db (db is only protected in zellij)", got)
+	}
+}
+
+func TestFindSession(t *testing.T) {
+	state := pickerState{shown: []backend.Session{{Name: "api", Clients: 2}}}
+	if s := state.find("api", ""); s.Clients != 2 {
+		t.Errorf("find(api) = %+v, want the listed session", s)
+	}
+	if s := state.find("gone", "tmux"); s.Name != "gone" || s.Backend != "tmux" {
+		t.Errorf("find(gone) = %+v", s)
+	}
+}
+
+// fastListBackend lists like zmx and zmosh: FastList has no client counts,
+// List does.
+type fastListBackend struct {
+	mockBackend
+	full []backend.Session
+}
+
+func (f *fastListBackend) Capabilities() backend.Capabilities {
+	return backend.Capabilities{ClientCounts: true, FastList: true}
+}
+func (f *fastListBackend) List() ([]backend.Session, error) { return f.full, nil }
+
+func TestWithClientCounts(t *testing.T) {
+	b := &fastListBackend{
+		mockBackend: mockBackend{name: "zmosh"},
+		full:        []backend.Session{{Name: "api", Clients: 2}, {Name: "db"}},
+	}
+	// What FastList gave the picker: no clients anywhere.
+	fast := listing("api", "db", "gone")
+
+	got := withClientCounts(b, fast)
+	if got[0].Clients != 2 || got[1].Clients != 0 {
+		t.Errorf("withClientCounts() = %+v, want api attached and db detached", got)
+	}
+	if got[2].Clients >= 0 {
+		t.Errorf("a session List doesn't know has Clients %d, want unknown", got[2].Clients)
+	}
+	if fast[0].Clients != 0 {
+		t.Error("withClientCounts() changed its argument")
+	}
+
+	// Backends without client counts are left as they are.
+	plain := withClientCounts(&mockBackend{name: "shpool"}, listing("api"))
+	if plain[0].Clients != 0 {
+		t.Errorf("Clients = %d for a backend without counts, want 0", plain[0].Clients)
+	}
+}
@@ -0,0 +1,103 @@
+package picker
+
+import (
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// refreshInterval is how often the picker re-lists sessions while it waits
+// for a key. Slow backends (ssh) are polled less often; see nextRefresh.
+const refreshInterval = 2 * time.Second
+
+// nextRefresh returns how long to wait before the next poll, given how
+// long the last listing took, so a backend is never busy more than a
+// fifth of the time.
+func nextRefresh(took time.Duration) time.Duration {
+	return max(refreshInterval, 5*took)
+}
+
+// sessionKey identifies a session across listings.
+func sessionKey(s backend.Session) string {
+	return s.Backend + "\x00" + s.Name
+}
+
+// arrange orders a fresh listing like the one on screen so key labels
+// don't move under the user's fingers: sessions still there keep their
+// places and new ones go at the end.
+//
+// With keepGone, sessions that disappeared stay in their slot and are
+// returned in gone, so nothing after them shifts; the picker drops them
+// on the next keypress. Without it, new sessions fill the vacated slots
+// first.
+func arrange(shown, fresh []backend.Session, keepGone bool) (sessions []backend.Session, gone map[string]bool) {
+	if len(shown) == 0 {
+		return fresh, nil
+	}
+	byKey := make(map[string]backend.Session, len(fresh))
+	for _, s := range fresh {
+		byKey[sessionKey(s)] = s
+	}
+	var added []backend.Session
+	for _, s := range fresh {
+		if !containsKey(shown, sessionKey(s)) {
+			added = append(added, s)
+		}
+	}
+
+	for _, old := range shown {
+		k := sessionKey(old)
+		if s, ok := byKey[k]; ok {
+			sessions = append(sessions, s)
+			continue
+		}
+		switch {
+		case keepGone:
+			if gone == nil {
+				gone = make(map[string]bool)
+			}
+			gone[k] = true
+			sessions = append(sessions, old)
+		case len(added) > 0:
+			sessions = append(sessions, added[0])
+			added = added[1:]
+		}
+	}
+	return append(sessions, added...), gone
+}
+
+func containsKey(sessions []backend.Session, key string) bool {
+	for _, s := range sessions {
+		if sessionKey(s) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// sameListing reports whether two listings would draw the same rows.
+// Timestamps are left out: zellij's creation time is derived from an age
+// and drifts between listings.
+func sameListing(a, b []backend.Session) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		if x.Name != y.Name || x.Backend != y.Backend || x.Active != y.Active ||
+			x.Clients != y.Clients || x.StartedIn != y.StartedIn || x.TaskEnded() != y.TaskEnded() ||
+			x.Dead != y.Dead || processCommand(x) != processCommand(y) {
+			return false
+		}
+	}
+	return true
+}
+
+// processCommand returns the command running in s, or "" if unknown. CPU
+// and memory change all the time and don't warrant a redraw on their own.
+func processCommand(s backend.Session) string {
+	if s.Process == nil {
+		return ""
+	}
+	return s.Process.Command
+}
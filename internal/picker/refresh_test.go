@@ -0,0 +1,73 @@
+package picker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func sessionNames(sessions []backend.Session) []string {
+	var out []string
+	for _, s := range sessions {
+		out = append(out, s.Name)
+	}
+	return out
+}
+
+func listing(names ...string) []backend.Session {
+	var sessions []backend.Session
+	for _, n := range names {
+		sessions = append(sessions, backend.Session{Name: n})
+	}
+	return sessions
+}
+
+func TestArrangeKeepsLabels(t *testing.T) {
+	shown := listing("api", "db", "web")
+
+	// A new session sorts first in the backend's listing but goes last.
+	got, gone := arrange(shown, listing("aaa", "api", "db", "web"), true)
+	if want := "api db web aaa"; strings.Join(sessionNames(got), " ") != want || gone != nil {
+		t.Errorf("arrange(new) = %v gone %v, want %s", sessionNames(got), gone, want)
+	}
+
+	// A vanished session keeps its slot while the picker waits.
+	got, gone = arrange(shown, listing("api", "web"), true)
+	if want := "api db web"; strings.Join(sessionNames(got), " ") != want || !gone[sessionKey(shown[1])] {
+		t.Errorf("arrange(gone) = %v gone %v, want %s with db gone", sessionNames(got), gone, want)
+	}
+
+	// On a re-list the slot goes to a new session instead.
+	got, _ = arrange(shown, listing("api", "new", "web"), false)
+	if want := "api new web"; strings.Join(sessionNames(got), " ") != want {
+		t.Errorf("arrange(relist) = %v, want %s", sessionNames(got), want)
+	}
+	got, _ = arrange(shown, listing("api", "web"), false)
+	if want := "api web"; strings.Join(sessionNames(got), " ") != want {
+		t.Errorf("arrange(relist, no new) = %v, want %s", sessionNames(got), want)
+	}
+}
+
+func TestSameListing(t *testing.T) {
+	now := time.Now()
+	a := []backend.Session{{Name: "x", CreatedAt: now}}
+	b := []backend.Session{{Name: "x", CreatedAt: now.Add(-time.Second)}}
+	if !sameListing(a, b) {
+		t.Error("creation time drift should not count as a change")
+	}
+	b[0].Clients = 1
+	if sameListing(a, b) {
+		t.Error("a new client should count as a change")
+	}
+}
+
+func TestNextRefresh(t *testing.T) {
+	if got := nextRefresh(10 * time.Millisecond); got != refreshInterval {
+		t.Errorf("nextRefresh(fast) = %v, want %v", got, refreshInterval)
+	}
+	if got := nextRefresh(time.Second); got != 5*time.Second {
+		t.Errorf("nextRefresh(1s) = %v, want 5s", got)
+	}
+}
@@ -0,0 +1,144 @@
+// Package procinfo describes what is running in a session from its PID:
+// the foreground command and the CPU and memory used by the session's
+// process tree. Snapshot is only implemented on Linux, where it reads
+// /proc; TTYOf falls back to ps elsewhere.
+package procinfo
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Info is what a session is running.
+type Info struct {
+	Command string  `json:"command"` // foreground command line, e.g. "cargo build"
+	CPU     float64 `json:"cpu"`     // percent of one CPU over the processes' lifetime, like ps
+	RSS     int64   `json:"rss"`     // resident memory in bytes, summed over the process tree
+}
+
+// proc is one process as read from the process table.
+type proc struct {
+	pid, ppid, pgrp, tpgid int
+	comm                   string
+	args                   []string
+	tty                    string // terminal on stdin, e.g. /dev/pts/3, or ""
+	cpu                    float64
+	rss                    int64
+}
+
+// Table is a snapshot of the processes on the machine.
+type Table struct {
+	procs    map[int]*proc
+	children map[int][]int
+}
+
+func newTable(procs []*proc) *Table {
+	t := &Table{procs: map[int]*proc{}, children: map[int][]int{}}
+	for _, p := range procs {
+		t.procs[p.pid] = p
+		t.children[p.ppid] = append(t.children[p.ppid], p.pid)
+	}
+	return t
+}
+
+// Inspect describes the process tree rooted at pid. The foreground command
+// is the deepest process in the tree that leads its terminal's foreground
+// process group, falling back to pid itself. ok is false if pid isn't in
+// the table.
+func (t *Table) Inspect(pid int) (info Info, ok bool) {
+	root := t.procs[pid]
+	if root == nil {
+		return Info{}, false
+	}
+	fg, fgDepth := root, -1
+	var walk func(p *proc, depth int)
+	walk = func(p *proc, depth int) {
+		info.CPU += p.cpu
+		info.RSS += p.rss
+		if p.tpgid > 0 && p.pid == p.tpgid && depth > fgDepth {
+			fg, fgDepth = p, depth
+		}
+		for _, c := range t.children[p.pid] {
+			if child := t.procs[c]; child != nil {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk(root, 0)
+	info.Command = commandLine(fg)
+	return info, true
+}
+
+// TTYOf returns the terminal the processes match selects run in, e.g.
+// /dev/pts/7. It returns "" if none of them has a terminal or they run in
+// different ones.
+func (t *Table) TTYOf(match func(args []string) bool) string {
+	tty := ""
+	for _, p := range t.procs {
+		if p.tty == "" || len(p.args) == 0 || !match(p.args) {
+			continue
+		}
+		if tty != "" && tty != p.tty {
+			return ""
+		}
+		tty = p.tty
+	}
+	return tty
+}
+
+// parsePS parses `ps -A -o pid=,tty=,args=` output into processes with
+// only a terminal and arguments, for where there is no /proc. A tty of
+// "??" or "-" means none.
+func parsePS(out string) []*proc {
+	var procs []*proc
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		p := &proc{pid: pid, args: fields[2:]}
+		if tty := fields[1]; tty != "??" && tty != "?" && tty != "-" {
+			p.tty = "/dev/" + tty
+		}
+		procs = append(procs, p)
+	}
+	return procs
+}
+
+// commandLine returns the command p runs, with the program's directory
+// dropped: "/usr/bin/cargo build" becomes "cargo build".
+func commandLine(p *proc) string {
+	if len(p.args) == 0 {
+		return p.comm
+	}
+	args := append([]string{filepath.Base(p.args[0])}, p.args[1:]...)
+	return strings.Join(args, " ")
+}
+
+// Summary formats the info for a session row, e.g. "cargo build 12% 340M".
+// Long commands are cut to keep rows narrow.
+func (i Info) Summary() string {
+	cmd := i.Command
+	if r := []rune(cmd); len(r) > 20 {
+		cmd = string(r[:19]) + "…"
+	}
+	return fmt.Sprintf("%s %.0f%% %s", cmd, i.CPU, formatBytes(i.RSS))
+}
+
+// formatBytes formats a memory size the way top does: 512K, 340M, 1.2G.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%dM", n>>20)
+	default:
+		return fmt.Sprintf("%dK", n>>10)
+	}
+}
@@ -0,0 +1,129 @@
+package procinfo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat.
+// It is 100 on every Linux architecture Go supports.
+const clockTicks = 100
+
+// TTYOf returns the terminal the processes match selects run in; see
+// Table.TTYOf.
+func TTYOf(match func(args []string) bool) (string, error) {
+	t, err := Snapshot()
+	if err != nil {
+		return "", err
+	}
+	return t.TTYOf(match), nil
+}
+
+// Snapshot reads the process table from /proc.
+func Snapshot() (*Table, error) {
+	return snapshotDir("/proc")
+}
+
+func snapshotDir(root string) (*Table, error) {
+	uptime, err := readUptime(root)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var procs []*proc
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		// Processes can exit while we read; skip whatever is gone.
+		p, err := readProc(filepath.Join(root, e.Name()), pid, uptime)
+		if err != nil {
+			continue
+		}
+		procs = append(procs, p)
+	}
+	return newTable(procs), nil
+}
+
+// readProc reads one process's stat and cmdline files.
+func readProc(dir string, pid int, uptime float64) (*proc, error) {
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	p, err := parseStat(string(stat), uptime)
+	if err != nil {
+		return nil, err
+	}
+	p.pid = pid
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		for _, arg := range bytes.Split(bytes.TrimRight(cmdline, "\x00"), []byte{0}) {
+			if len(arg) > 0 {
+				p.args = append(p.args, string(arg))
+			}
+		}
+	}
+	// Only a process's owner can read its fds, which covers the clients
+	// we look for.
+	if target, err := os.Readlink(filepath.Join(dir, "fd", "0")); err == nil && isTerminal(target) {
+		p.tty = target
+	}
+	return p, nil
+}
+
+// isTerminal reports whether path names a terminal device.
+func isTerminal(path string) bool {
+	return strings.HasPrefix(path, "/dev/pts/") || strings.HasPrefix(path, "/dev/tty")
+}
+
+// parseStat parses /proc/<pid>/stat. The command name is in parentheses
+// and may itself contain spaces and parentheses, so fields are counted
+// from the last ')'.
+func parseStat(stat string, uptime float64) (*proc, error) {
+	open, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return nil, fmt.Errorf("malformed stat")
+	}
+	fields := strings.Fields(stat[end+1:])
+	// fields[0] is the state; the rest follow proc(5) from ppid on.
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("short stat")
+	}
+	num := func(i int) int64 {
+		n, _ := strconv.ParseInt(fields[i], 10, 64)
+		return n
+	}
+	p := &proc{
+		comm:  stat[open+1 : end],
+		ppid:  int(num(1)),
+		pgrp:  int(num(2)),
+		tpgid: int(num(5)),
+		rss:   num(21) * int64(os.Getpagesize()),
+	}
+	cpuSeconds := float64(num(11)+num(12)) / clockTicks
+	if elapsed := uptime - float64(num(19))/clockTicks; elapsed > 0 {
+		p.cpu = 100 * cpuSeconds / elapsed
+	}
+	return p, nil
+}
+
+// readUptime returns the seconds since boot from /proc/uptime.
+func readUptime(root string) (float64, error) {
+	data, err := os.ReadFile(filepath.Join(root, "uptime"))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("malformed uptime")
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
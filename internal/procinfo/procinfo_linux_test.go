@@ -0,0 +1,115 @@
+package procinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeProc fakes /proc/<pid> under root. Times are in clock ticks.
+func writeProc(t *testing.T, root, pid, comm string, ppid, pgrp, tpgid, utime, starttime, rssPages int, args ...string) {
+	t.Helper()
+	dir := filepath.Join(root, pid)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// pid (comm) state ppid pgrp session tty_nr tpgid flags minflt cminflt
+	// majflt cmajflt utime stime cutime cstime priority nice num_threads
+	// itrealvalue starttime vsize rss
+	stat := strings.Join([]string{
+		pid, "(" + comm + ")", "S", strconv.Itoa(ppid), strconv.Itoa(pgrp), "1", "34816", strconv.Itoa(tpgid),
+		"0", "0", "0", "0", "0", strconv.Itoa(utime), "0", "0", "0", "20", "0", "1", "0",
+		strconv.Itoa(starttime), "0", strconv.Itoa(rssPages),
+	}, " ")
+	os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644)
+	os.WriteFile(filepath.Join(dir, "cmdline"), []byte(strings.Join(args, "\x00")+"\x00"), 0o644)
+}
+
+func TestInspect(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "uptime"), []byte("1000.00 900.00\n"), 0o644)
+
+	// A shell running cargo build in the foreground, plus a background job.
+	writeProc(t, root, "100", "zsh", 1, 100, 200, 1000, 0, 10, "/bin/zsh")
+	writeProc(t, root, "200", "cargo", 100, 200, 200, 5000, 50000, 100, "/usr/bin/cargo", "build")
+	writeProc(t, root, "300", "sleep", 100, 300, 200, 0, 90000, 5, "sleep", "600")
+	writeProc(t, root, "400", "other", 1, 400, 400, 0, 0, 1, "other")
+
+	table, err := snapshotDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, ok := table.Inspect(100)
+	if !ok {
+		t.Fatal("Inspect(100) found nothing")
+	}
+	if info.Command != "cargo build" {
+		t.Errorf("Command = %q, want cargo build", info.Command)
+	}
+	if want := int64(115 * os.Getpagesize()); info.RSS != want {
+		t.Errorf("RSS = %d, want %d", info.RSS, want)
+	}
+	// zsh: 10s of CPU over 1000s; cargo: 50s over 500s.
+	if info.CPU < 10.9 || info.CPU > 11.1 {
+		t.Errorf("CPU = %.2f, want 11", info.CPU)
+	}
+
+	if _, ok := table.Inspect(999); ok {
+		t.Error("Inspect of a missing pid should fail")
+	}
+}
+
+func TestParseStatCommWithParens(t *testing.T) {
+	p, err := parseStat("42 (my (odd) prog) R 1 42 42 0 -1 0 0 0 0 0 0 0 0 0 20 0 1 0 0 0 3", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.comm != "my (odd) prog" || p.ppid != 1 || p.tpgid != -1 {
+		t.Errorf("parseStat() = %+v", p)
+	}
+}
+
+func TestTTYOf(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "uptime"), []byte("1000.00 900.00\n"), 0o644)
+	withTTY := func(pid, tty string) {
+		os.MkdirAll(filepath.Join(root, pid, "fd"), 0o755)
+		if err := os.Symlink(tty, filepath.Join(root, pid, "fd", "0")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// work was created from the shell on pts/3 and is attached from pts/7.
+	writeProc(t, root, "100", "zmx", 1, 100, 100, 0, 0, 1, "zmx", "attach", "work")
+	withTTY("100", "/dev/pts/7")
+	writeProc(t, root, "200", "zsh", 1, 200, 200, 0, 0, 1, "/bin/zsh")
+	withTTY("200", "/dev/pts/3")
+	writeProc(t, root, "300", "zmx", 1, 300, 300, 0, 0, 1, "zmx", "attach", "play")
+	withTTY("300", "/dev/null")
+
+	table, err := snapshotDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attaches := func(name string) func([]string) bool {
+		return func(args []string) bool { return len(args) == 3 && args[1] == "attach" && args[2] == name }
+	}
+	if got := table.TTYOf(attaches("work")); got != "/dev/pts/7" {
+		t.Errorf("TTYOf(work) = %q, want /dev/pts/7", got)
+	}
+	if got := table.TTYOf(attaches("play")); got != "" {
+		t.Errorf("TTYOf(play) = %q, want empty for a client without a terminal", got)
+	}
+
+	// Attached from two terminals at once: no single answer.
+	writeProc(t, root, "400", "zmx", 1, 400, 400, 0, 0, 1, "zmx", "attach", "work")
+	withTTY("400", "/dev/pts/9")
+	if table, err = snapshotDir(root); err != nil {
+		t.Fatal(err)
+	}
+	if got := table.TTYOf(attaches("work")); got != "" {
+		t.Errorf("TTYOf(work) = %q, want empty for two clients", got)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package procinfo
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// Snapshot is only implemented on Linux.
+func Snapshot() (*Table, error) {
+	return nil, fmt.Errorf("process info on %s: %w", runtime.GOOS, errors.ErrUnsupported)
+}
+
+// TTYOf returns the terminal the processes match selects run in; see
+// Table.TTYOf. Without /proc the process list comes from ps, whose
+// arguments are split on spaces.
+func TTYOf(match func(args []string) bool) (string, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=,tty=,args=").Output()
+	if err != nil {
+		return "", err
+	}
+	return newTable(parsePS(string(out))).TTYOf(match), nil
+}
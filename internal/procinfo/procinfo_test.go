@@ -0,0 +1,26 @@
+package procinfo
+
+import "testing"
+
+func TestSummary(t *testing.T) {
+	info := Info{Command: "cargo build --release --workspace", CPU: 12.4, RSS: 340 << 20}
+	if got := info.Summary(); got != "cargo build --relea… 12% 340M" {
+		t.Errorf("Summary() = %q", got)
+	}
+	if got := (Info{Command: "vim", RSS: 1536 << 20}).Summary(); got != "vim 0% 1.5G" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestParsePS(t *testing.T) {
+	out := "  412 ttys003  zmx attach work\n  398 ??       /usr/local/bin/zmx --server work\n  377 ttys007  -zsh\n"
+	table := newTable(parsePS(out))
+	attaches := func(args []string) bool { return len(args) == 3 && args[1] == "attach" && args[2] == "work" }
+	if got := table.TTYOf(attaches); got != "/dev/ttys003" {
+		t.Errorf("TTYOf(attach work) = %q, want /dev/ttys003", got)
+	}
+	server := func(args []string) bool { return len(args) > 1 && args[1] == "--server" }
+	if got := table.TTYOf(server); got != "" {
+		t.Errorf("TTYOf(server) = %q, want empty for a process without a terminal", got)
+	}
+}
@@ -0,0 +1,108 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// Host is a remote machine zp can list sessions on.
+type Host struct {
+	Name      string // ssh destination (alias from ~/.ssh/config or user@host)
+	Transport string // "ssh" (default) or "mosh", used for attaching
+	Backend   string // backend on the remote host; empty means the local one
+}
+
+// HostsPath returns the path to the hosts config file.
+func HostsPath() string {
+	return filepath.Join(backend.ConfigDir(), "hosts")
+}
+
+// ReadHosts returns the configured remote hosts.
+// Reads ~/.config/zpick/hosts if it exists, otherwise falls back to the
+// concrete Host entries in ~/.ssh/config.
+func ReadHosts() ([]Host, error) {
+	data, err := os.ReadFile(HostsPath())
+	if err == nil {
+		return parseHosts(string(data)), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cannot read hosts config: %w", err)
+	}
+
+	home, _ := os.UserHomeDir()
+	data, err = os.ReadFile(filepath.Join(home, ".ssh", "config"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ssh config: %w", err)
+	}
+	var hosts []Host
+	for _, name := range parseSSHConfig(string(data)) {
+		hosts = append(hosts, Host{Name: name, Transport: "ssh"})
+	}
+	return hosts, nil
+}
+
+// FindHost returns the configured host with the given name.
+// Unknown names are returned as plain ssh hosts so any ssh destination works.
+func FindHost(name string) Host {
+	hosts, _ := ReadHosts()
+	for _, h := range hosts {
+		if h.Name == name {
+			return h
+		}
+	}
+	return Host{Name: name, Transport: "ssh"}
+}
+
+// parseHosts parses the hosts config file.
+// Each line is: <host> [ssh|mosh] [backend]. Blank lines and # comments are skipped.
+func parseHosts(content string) []Host {
+	var hosts []Host
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		h := Host{Name: fields[0], Transport: "ssh"}
+		if len(fields) >= 2 && fields[1] == "mosh" {
+			h.Transport = "mosh"
+		}
+		if len(fields) >= 3 {
+			h.Backend = fields[2]
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
+// parseSSHConfig extracts concrete host aliases from ssh_config content.
+// Wildcard and negated patterns are skipped since they don't name a host.
+func parseSSHConfig(content string) []string {
+	var hosts []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		key, rest, ok := strings.Cut(line, " ")
+		if !ok {
+			key, rest, ok = strings.Cut(line, "=")
+		}
+		if !ok || !strings.EqualFold(key, "Host") {
+			continue
+		}
+		for _, name := range strings.Fields(strings.TrimLeft(rest, " =")) {
+			if strings.ContainsAny(name, "*?!") || seen[name] {
+				continue
+			}
+			hosts = append(hosts, name)
+			seen[name] = true
+		}
+	}
+	return hosts
+}
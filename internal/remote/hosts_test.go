@@ -0,0 +1,87 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseHosts(t *testing.T) {
+	content := "# build boxes\nbuild1\nbuild2 mosh\n\ngpu ssh tmux\n"
+	hosts := parseHosts(content)
+	if len(hosts) != 3 {
+		t.Fatalf("expected 3 hosts, got %d", len(hosts))
+	}
+	if hosts[0] != (Host{Name: "build1", Transport: "ssh"}) {
+		t.Errorf("hosts[0] = %+v", hosts[0])
+	}
+	if hosts[1].Transport != "mosh" {
+		t.Errorf("hosts[1].Transport = %q, want mosh", hosts[1].Transport)
+	}
+	if hosts[2].Backend != "tmux" {
+		t.Errorf("hosts[2].Backend = %q, want tmux", hosts[2].Backend)
+	}
+}
+
+func TestParseSSHConfig(t *testing.T) {
+	content := `Host *
+  ServerAliveInterval 30
+
+Host build1 build2
+  HostName 10.0.0.1
+
+host=gpu
+Host *.internal !bastion
+Match host foo
+Host build1
+`
+	got := parseSSHConfig(content)
+	want := []string{"build1", "build2", "gpu"}
+	if len(got) != len(want) {
+		t.Fatalf("parseSSHConfig() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("host %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadHostsPrefersConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	os.MkdirAll(filepath.Join(dir, ".ssh"), 0o755)
+	os.WriteFile(filepath.Join(dir, ".ssh", "config"), []byte("Host fromssh\n"), 0o644)
+
+	hosts, err := ReadHosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != "fromssh" {
+		t.Errorf("expected ssh config fallback, got %+v", hosts)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "zpick"), 0o755)
+	os.WriteFile(HostsPath(), []byte("configured mosh\n"), 0o644)
+
+	hosts, err = ReadHosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != "configured" || hosts[0].Transport != "mosh" {
+		t.Errorf("expected hosts file to win, got %+v", hosts)
+	}
+}
+
+func TestFindHostUnknown(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	h := FindHost("user@somewhere")
+	if h.Name != "user@somewhere" || h.Transport != "ssh" {
+		t.Errorf("FindHost() = %+v, want plain ssh host", h)
+	}
+}
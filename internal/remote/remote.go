@@ -0,0 +1,231 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/shell"
+)
+
+// sshOptions keep listing and killing non-interactive so a host that wants
+// a password or is unreachable fails fast instead of hanging the picker.
+var sshOptions = []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5"}
+
+// renamer is implemented by backends whose rename is a single command
+// that can be run over ssh.
+type renamer interface {
+	RenameArgs(oldName, newName string) []string
+}
+
+// capturer is implemented by backends whose screen capture is a single
+// command that prints to stdout.
+type capturer interface {
+	CaptureArgs(name string, lines int) []string
+}
+
+// Backend runs another backend's CLI on a remote host over ssh.
+// Sessions are listed and killed with ssh; attaching goes through
+// ssh -t or mosh depending on the host's transport.
+type Backend struct {
+	host  Host
+	inner backend.Backend
+	cmds  backend.Remotable
+}
+
+// New wraps inner so it operates on host.
+func New(host Host, inner backend.Backend) (*Backend, error) {
+	cmds, ok := inner.(backend.Remotable)
+	if !ok {
+		return nil, fmt.Errorf("backend %s can't be used over ssh", inner.Name())
+	}
+	return &Backend{host: host, inner: inner, cmds: cmds}, nil
+}
+
+// Name returns e.g. "tmux@buildbox".
+func (r *Backend) Name() string          { return r.inner.Name() + "@" + r.host.Name }
+func (r *Backend) BinaryName() string    { return r.host.Transport }
+func (r *Backend) SessionEnvVar() string { return r.inner.SessionEnvVar() }
+
+// InSession is always false: the local shell is never inside a remote session.
+func (r *Backend) InSession() bool            { return false }
+func (r *Backend) CurrentSessionName() string { return "" }
+
+func (r *Backend) Available() (bool, error) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		return false, fmt.Errorf("ssh not found in PATH")
+	}
+	if r.host.Transport == "mosh" {
+		if _, err := exec.LookPath("mosh"); err != nil {
+			return false, fmt.Errorf("mosh not found in PATH")
+		}
+	}
+	return true, nil
+}
+
+// Capabilities are the inner backend's, minus what doesn't survive ssh:
+// there is no socket directory to scan and no local client to switch.
+func (r *Backend) Capabilities() backend.Capabilities {
+	c := r.inner.Capabilities()
+	c.FastList = false
+	c.NativeSwitch = false
+	if _, ok := r.inner.(renamer); !ok {
+		c.Rename = false
+	}
+	if _, ok := r.inner.(capturer); !ok {
+		c.Capture = false
+	}
+	if _, ok := r.inner.(backend.ReadOnlyAttacher); !ok {
+		c.ReadOnlyAttach = false
+	}
+	return c
+}
+
+func (r *Backend) Version() (string, error) {
+	return "", fmt.Errorf("version of %s on %s is not available over ssh", r.inner.Name(), r.host.Name)
+}
+
+func (r *Backend) List() ([]backend.Session, error) {
+	out, err := r.run(r.cmds.ListArgs())
+	if err != nil {
+		// A non-zero exit with no output is how most backends say "no sessions"
+		// (tmux exits 1 when its server isn't running). 255 is ssh's own failure.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() != 255 && strings.TrimSpace(out) == "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list sessions on %s: %w", r.host.Name, err)
+	}
+	sessions := r.cmds.ParseList(out)
+	for i := range sessions {
+		sessions[i].Host = r.host.Name
+	}
+	return sessions, nil
+}
+
+// FastList is the same as List for remote hosts (no socket shortcut over ssh).
+func (r *Backend) FastList() ([]backend.Session, error) {
+	return r.List()
+}
+
+func (r *Backend) Attach(name string) error {
+	return backend.Exec(r.AttachCommand(name, ""))
+}
+
+// AttachCommand wraps the inner backend's attach command in ssh -t or mosh.
+// The directory is applied on the remote side, not locally.
+func (r *Backend) AttachCommand(name, dir string) shell.Command {
+	return shell.Command{Args: r.attachArgv(r.inner.AttachCommand(name, dir))}
+}
+
+// ReadOnlyAttachCommand wraps the inner backend's read-only attach command
+// in ssh -t or mosh. Capabilities only offers it when the inner backend
+// has one.
+func (r *Backend) ReadOnlyAttachCommand(name string) shell.Command {
+	ro, ok := r.inner.(backend.ReadOnlyAttacher)
+	if !ok {
+		return shell.Command{}
+	}
+	return shell.Command{Args: r.attachArgv(ro.ReadOnlyAttachCommand(name))}
+}
+
+func (r *Backend) DetachCommand() shell.Command { return r.inner.DetachCommand() }
+
+func (r *Backend) Kill(name string) error {
+	if _, err := r.run(r.cmds.KillArgs(name)); err != nil {
+		return fmt.Errorf("failed to kill %s on %s: %w", name, r.host.Name, err)
+	}
+	return nil
+}
+
+// Rename runs the inner backend's rename command on the host, if it has one.
+func (r *Backend) Rename(oldName, newName string) error {
+	rn, ok := r.inner.(renamer)
+	if !ok {
+		return backend.Unsupported(r, "rename sessions")
+	}
+	if _, err := r.run(rn.RenameArgs(oldName, newName)); err != nil {
+		return fmt.Errorf("failed to rename %s on %s: %w", oldName, r.host.Name, err)
+	}
+	return nil
+}
+
+// Capture runs the inner backend's capture command on the host, if it has one.
+func (r *Backend) Capture(name string, lines int) (string, error) {
+	c, ok := r.inner.(capturer)
+	if !ok {
+		return "", backend.Unsupported(r, "capture output")
+	}
+	out, err := r.run(c.CaptureArgs(name, lines))
+	if err != nil {
+		return "", fmt.Errorf("failed to capture %s on %s: %w", name, r.host.Name, err)
+	}
+	return backend.LastLines(out, lines), nil
+}
+
+// attachArgv builds the local argv that runs cmd interactively on the host.
+// ssh hands its command to the remote login shell; mosh execs it directly,
+// so it is wrapped in sh -c. Either way the remote side parses it as POSIX.
+func (r *Backend) attachArgv(cmd shell.Command) []string {
+	remoteCmd := cmd.Render(shell.POSIX)
+	if r.host.Transport == "mosh" {
+		return []string{"mosh", r.host.Name, "--", "sh", "-c", remoteCmd}
+	}
+	return []string{"ssh", "-t", r.host.Name, "--", remoteCmd}
+}
+
+// run executes argv on the host and returns its stdout.
+func (r *Backend) run(argv []string) (string, error) {
+	args := append(append([]string{}, sshOptions...), r.host.Name, "--", shell.Join(shell.POSIX, argv))
+	out, err := exec.Command("ssh", args...).Output()
+	return string(out), err
+}
+
+// Load returns a remote backend for host. The host's configured backend is
+// used if set; otherwise local, the locally configured backend, is reused.
+func Load(host Host, local func() (backend.Backend, error)) (backend.Backend, error) {
+	var inner backend.Backend
+	var err error
+	if host.Backend != "" {
+		inner, err = backend.New(host.Backend)
+	} else {
+		inner, err = local()
+	}
+	if err != nil {
+		return nil, err
+	}
+	r, err := New(host, inner)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LoadAll returns a remote backend for every configured host.
+// Hosts whose backend can't be driven over ssh are skipped; it is an
+// error if none of them load.
+func LoadAll(local func() (backend.Backend, error)) ([]backend.Backend, error) {
+	hosts, err := ReadHosts()
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no remote hosts configured (add them to %s)", HostsPath())
+	}
+	var members []backend.Backend
+	var errs []error
+	for _, h := range hosts {
+		b, err := Load(h, local)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.Name, err))
+			continue
+		}
+		members = append(members, b)
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no remote host could be loaded: %w", errors.Join(errs...))
+	}
+	return members, nil
+}
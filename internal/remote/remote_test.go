@@ -0,0 +1,178 @@
+package remote
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/tmux"
+	"github.com/nerveband/zpick/internal/backend/zmosh"
+)
+
+var _ backend.Backend = (*Backend)(nil)
+
+// fakeSSH puts an "ssh" script on PATH that records its arguments and
+// prints output, exiting with code. Returns the path of the args log.
+func fakeSSH(t *testing.T, output string, code int) string {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args")
+	out := filepath.Join(dir, "out")
+	if err := os.WriteFile(out, []byte(output), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\n" +
+		"for a in \"$@\"; do printf '%s\\n' \"$a\"; done > " + log + "\n" +
+		"cat " + out + "\n" +
+		"exit " + strconv.Itoa(code) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func readArgs(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestRemoteListTmux(t *testing.T) {
+	log := fakeSSH(t, "work\t1\t/srv/work\nplay\t0\t/srv/play\n", 0)
+
+	r, err := New(Host{Name: "build1", Transport: "ssh"}, tmux.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].Name != "work" || sessions[0].Host != "build1" {
+		t.Errorf("unexpected first session: %+v", sessions[0])
+	}
+	if sessions[1].StartedIn != "/srv/play" {
+		t.Errorf("StartedIn = %q, want /srv/play", sessions[1].StartedIn)
+	}
+
+	args := readArgs(t, log)
+	remoteCmd := args[len(args)-1]
+	if !strings.HasPrefix(remoteCmd, "tmux list-sessions -F '") {
+		t.Errorf("remote command should be the quoted tmux list: %q", remoteCmd)
+	}
+	if args[len(args)-3] != "build1" || args[len(args)-2] != "--" {
+		t.Errorf("expected host and -- before command, got %q", args)
+	}
+}
+
+func TestRemoteListZmosh(t *testing.T) {
+	fakeSSH(t, "  session_name=api\tpid=42\tclients=1\tstarted_in=~/api\n", 0)
+
+	r, _ := New(Host{Name: "box", Transport: "ssh"}, zmosh.New())
+	sessions, err := r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "api" || sessions[0].PID != 42 {
+		t.Errorf("unexpected sessions: %+v", sessions)
+	}
+}
+
+func TestRemoteListNoServer(t *testing.T) {
+	// tmux exits 1 with no output when no server is running
+	fakeSSH(t, "", 1)
+
+	r, _ := New(Host{Name: "build1", Transport: "ssh"}, tmux.New())
+	sessions, err := r.List()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("expected 0 sessions, got %d", len(sessions))
+	}
+}
+
+func TestRemoteKill(t *testing.T) {
+	log := fakeSSH(t, "", 0)
+
+	r, _ := New(Host{Name: "build1", Transport: "ssh"}, tmux.New())
+	if err := r.Kill("it's mine"); err != nil {
+		t.Fatal(err)
+	}
+	args := readArgs(t, log)
+	want := `tmux kill-session -t '=it'\''s mine'`
+	if got := args[len(args)-1]; got != want {
+		t.Errorf("remote command = %q, want %q", got, want)
+	}
+}
+
+func TestRemoteAttachCommand(t *testing.T) {
+	r, _ := New(Host{Name: "build1", Transport: "ssh"}, tmux.New())
+	got := r.AttachCommand("my work", "").String()
+	want := `ssh -t build1 -- 'tmux new-session -A -s '\''my work'\'''`
+	if got != want {
+		t.Errorf("AttachCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestRemoteAttachCommandMosh(t *testing.T) {
+	r, _ := New(Host{Name: "build1", Transport: "mosh"}, tmux.New())
+	got := r.AttachCommand("work", "").String()
+	want := `mosh build1 -- sh -c 'tmux new-session -A -s work'`
+	if got != want {
+		t.Errorf("AttachCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestRemoteReadOnlyAttach(t *testing.T) {
+	r, _ := New(Host{Name: "build1", Transport: "ssh"}, tmux.New())
+	if !r.Capabilities().ReadOnlyAttach {
+		t.Error("tmux over ssh should offer read-only attach")
+	}
+	got := r.ReadOnlyAttachCommand("work").String()
+	want := `ssh -t build1 -- 'tmux attach-session -r -t '\''=work'\'''`
+	if got != want {
+		t.Errorf("ReadOnlyAttachCommand() = %q, want %q", got, want)
+	}
+
+	z, _ := New(Host{Name: "box", Transport: "ssh"}, zmosh.New())
+	if z.Capabilities().ReadOnlyAttach {
+		t.Error("zmosh over ssh can't attach read-only")
+	}
+}
+
+func TestRemoteName(t *testing.T) {
+	r, _ := New(Host{Name: "build1", Transport: "ssh"}, tmux.New())
+	if r.Name() != "tmux@build1" {
+		t.Errorf("Name() = %q, want tmux@build1", r.Name())
+	}
+	if r.InSession() {
+		t.Error("remote backend should never report InSession")
+	}
+}
+
+func TestLoadAllFailsWhenNoHostLoads(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	os.MkdirAll(filepath.Join(dir, "zpick"), 0o755)
+	os.WriteFile(HostsPath(), []byte("build1 ssh nosuchbackend\nbuild2 ssh nosuchbackend\n"), 0o644)
+
+	members, err := LoadAll(func() (backend.Backend, error) { return nil, errors.New("unused") })
+	if err == nil {
+		t.Fatalf("LoadAll() = %d members, want an error", len(members))
+	}
+	if !strings.Contains(err.Error(), "build1") || !strings.Contains(err.Error(), "build2") {
+		t.Errorf("error %q should name the failing hosts", err)
+	}
+}
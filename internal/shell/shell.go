@@ -0,0 +1,136 @@
+// Package shell renders structured commands as shell source.
+//
+// zp prints commands that the shell hook evals, so every session name and
+// directory that ends up in that output must be quoted for the shell doing
+// the eval. Commands are built as argv and only turned into text here.
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Dialect is a family of shells that share quoting rules.
+type Dialect string
+
+const (
+	POSIX Dialect = "posix" // sh, bash, zsh
+	Fish  Dialect = "fish"
+)
+
+// Command is a command to run: argv plus optional working directory and
+// extra environment. Exec replaces the shell instead of running a child.
+type Command struct {
+	Args []string
+	Dir  string
+	Env  []string // "KEY=value" entries
+	Exec bool
+}
+
+// ForShell returns the dialect for a shell name or path, e.g. "/bin/zsh".
+func ForShell(name string) Dialect {
+	if filepath.Base(name) == "fish" {
+		return Fish
+	}
+	return POSIX
+}
+
+// Current returns the dialect of the shell that will eval zp's output.
+// The shell hook exports ZPICK_SHELL; $SHELL is the fallback.
+func Current() Dialect {
+	if d := os.Getenv("ZPICK_SHELL"); d != "" {
+		return ForShell(d)
+	}
+	return ForShell(os.Getenv("SHELL"))
+}
+
+// IsZero reports whether c has nothing to run.
+func (c Command) IsZero() bool {
+	return len(c.Args) == 0
+}
+
+// Render returns c as a single line of shell source for dialect d.
+func (c Command) Render(d Dialect) string {
+	if c.IsZero() {
+		return ""
+	}
+	var b strings.Builder
+	if c.Dir != "" {
+		b.WriteString("cd ")
+		b.WriteString(Quote(d, c.Dir))
+		b.WriteString(" && ")
+	}
+	if c.Exec {
+		b.WriteString("exec ")
+	}
+	if len(c.Env) > 0 {
+		b.WriteString("env ")
+		for _, kv := range c.Env {
+			b.WriteString(Quote(d, kv))
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteString(Join(d, c.Args))
+	return b.String()
+}
+
+// String renders c for a POSIX shell.
+func (c Command) String() string {
+	return c.Render(POSIX)
+}
+
+// Join quotes each argument for dialect d and joins them with spaces.
+func Join(d Dialect, args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = Quote(d, a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Quote quotes s as a single word for dialect d. Words made only of
+// characters no shell treats specially are returned unchanged.
+func Quote(d Dialect, s string) string {
+	if isSafe(s) {
+		return s
+	}
+	if d == Fish {
+		return quoteFish(s)
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish single-quotes s for fish, where \ and ' are escaped inside
+// quotes. Newlines are written as \n outside the quotes so the result
+// stays on one line: the fish hook's command substitution splits on them.
+func quoteFish(s string) string {
+	if s == "" {
+		return "''"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "'" + r.Replace(line) + "'"
+		}
+	}
+	return strings.Join(lines, `\n`)
+}
+
+// isSafe reports whether s can be written unquoted. A leading '=' is not:
+// zsh expands =cmd to the command's path.
+func isSafe(s string) bool {
+	if s == "" || s[0] == '=' {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("-_./=@:,+%", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
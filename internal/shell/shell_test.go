@@ -0,0 +1,189 @@
+package shell
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	cmd := Command{
+		Args: []string{"tmux", "new-session", "-A", "-s", "my work"},
+		Dir:  "/tmp/it's here",
+		Env:  []string{"ZPICK_AUTORUN=abc=="},
+		Exec: true,
+	}
+	want := `cd '/tmp/it'\''s here' && exec env ZPICK_AUTORUN=abc== tmux new-session -A -s 'my work'`
+	if got := cmd.Render(POSIX); got != want {
+		t.Errorf("Render(POSIX) = %q, want %q", got, want)
+	}
+	want = `cd '/tmp/it\'s here' && exec env ZPICK_AUTORUN=abc== tmux new-session -A -s 'my work'`
+	if got := cmd.Render(Fish); got != want {
+		t.Errorf("Render(Fish) = %q, want %q", got, want)
+	}
+}
+
+func TestRenderZero(t *testing.T) {
+	if got := (Command{Dir: "/tmp", Exec: true}).Render(POSIX); got != "" {
+		t.Errorf("zero command rendered as %q", got)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in, posix, fish string
+	}{
+		{"work", "work", "work"},
+		{"~/src/api", "'~/src/api'", "'~/src/api'"},
+		{"", "''", "''"},
+		{`$(rm -rf ~)`, `'$(rm -rf ~)'`, `'$(rm -rf ~)'`},
+		{"a`b`", "'a`b`'", "'a`b`'"},
+		{`a"b`, `'a"b'`, `'a"b'`},
+		{`a\b`, `'a\b'`, `'a\\b'`},
+		{"a'b", `'a'\''b'`, `'a\'b'`},
+		{"a\nb", "'a\nb'", `'a'\n'b'`},
+		{"=ls", "'=ls'", "'=ls'"},
+		{"a=b", "a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		if got := Quote(POSIX, tt.in); got != tt.posix {
+			t.Errorf("Quote(POSIX, %q) = %q, want %q", tt.in, got, tt.posix)
+		}
+		if got := Quote(Fish, tt.in); got != tt.fish {
+			t.Errorf("Quote(Fish, %q) = %q, want %q", tt.in, got, tt.fish)
+		}
+	}
+}
+
+func TestForShell(t *testing.T) {
+	if ForShell("/usr/local/bin/fish") != Fish {
+		t.Error("fish path should map to Fish")
+	}
+	for _, sh := range []string{"zsh", "/bin/bash", "sh", "posix", ""} {
+		if ForShell(sh) != POSIX {
+			t.Errorf("ForShell(%q) should be POSIX", sh)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/fish")
+	t.Setenv("ZPICK_SHELL", "")
+	if Current() != Fish {
+		t.Error("Current() should fall back to $SHELL")
+	}
+	t.Setenv("ZPICK_SHELL", "posix")
+	if Current() != POSIX {
+		t.Error("ZPICK_SHELL should override $SHELL")
+	}
+}
+
+var fuzzSeeds = []string{
+	"work",
+	"my session",
+	`$(touch /tmp/pwned)`,
+	"`id`",
+	`"; rm -rf ~; echo "`,
+	`'; rm -rf ~; echo '`,
+	`\'`,
+	`a\`,
+	"line1\nline2",
+	"$HOME",
+	"*",
+	"-n",
+	"~",
+	"~root",
+	"=ls",
+	"{a,b}",
+	"é 日本",
+}
+
+// FuzzQuotePOSIX runs the rendered command through every POSIX shell
+// installed and checks the name comes back byte for byte.
+func FuzzQuotePOSIX(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	var shells []string
+	for _, sh := range []string{"sh", "bash", "zsh"} {
+		if path, err := exec.LookPath(sh); err == nil {
+			shells = append(shells, path)
+		}
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		if strings.ContainsRune(name, 0) {
+			t.Skip("argv can't contain NUL")
+		}
+		src := Command{Args: []string{"printf", "%s", name}}.Render(POSIX)
+		for _, sh := range shells {
+			out, err := exec.Command(sh, "-c", src).Output()
+			if err != nil {
+				t.Fatalf("%s -c %q: %v", sh, src, err)
+			}
+			if string(out) != name {
+				t.Fatalf("%s: %q printed %q, want %q", sh, src, out, name)
+			}
+		}
+	})
+}
+
+// FuzzQuoteFish checks fish quoting with a reference unquoter, and with
+// fish itself when it is installed.
+func FuzzQuoteFish(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	fish, _ := exec.LookPath("fish")
+	f.Fuzz(func(t *testing.T, name string) {
+		if strings.ContainsRune(name, 0) {
+			t.Skip("argv can't contain NUL")
+		}
+		q := Quote(Fish, name)
+		if strings.Contains(q, "\n") {
+			t.Fatalf("Quote(Fish, %q) = %q spans lines", name, q)
+		}
+		got, ok := unquoteFish(q)
+		if !ok || got != name {
+			t.Fatalf("Quote(Fish, %q) = %q, unquotes to %q (ok=%v)", name, q, got, ok)
+		}
+		if fish != "" {
+			out, err := exec.Command(fish, "--no-config", "-c", "printf %s "+q).Output()
+			if err != nil || string(out) != name {
+				t.Fatalf("fish printed %q (%v), want %q", out, err, name)
+			}
+		}
+	})
+}
+
+// unquoteFish parses a single fish word made of safe characters, \n
+// escapes and single-quoted strings. It fails on anything else, so a
+// successful parse means the word has no expansions or separators.
+func unquoteFish(word string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(word); i++ {
+		c := word[i]
+		switch {
+		case c == '\'':
+			for i++; ; i++ {
+				if i >= len(word) {
+					return "", false
+				}
+				if word[i] == '\'' {
+					break
+				}
+				if word[i] == '\\' && i+1 < len(word) && (word[i+1] == '\\' || word[i+1] == '\'') {
+					i++
+				}
+				b.WriteByte(word[i])
+			}
+		case c == '\\' && i+1 < len(word) && word[i+1] == 'n':
+			b.WriteByte('\n')
+			i++
+		case isSafe(string(c)):
+			b.WriteByte(c)
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
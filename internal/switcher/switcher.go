@@ -5,49 +5,136 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
 // Target describes what session to switch to after detaching.
 type Target struct {
-	Action string `json:"action"` // "attach" or "new"
-	Name   string `json:"name"`
-	Dir    string `json:"dir,omitempty"`
+	Action  string `json:"action"` // "attach" or "new"
+	Name    string `json:"name"`
+	Dir     string `json:"dir,omitempty"`
+	Backend string `json:"backend,omitempty"` // backend owning the session; empty for the configured one
 }
 
-// filePath is the switch-target file location.
-var filePath string
+// Targets are kept one file per client, so two terminals switching at the
+// same time don't take each other's target. A client is the terminal the
+// detaching session client runs in, named by its tty. Nothing a session
+// inherits from the shell that created it can name the client, since the
+// session may be attached from another terminal by now.
+const (
+	// DefaultKey is the key of targets written when the client's tty isn't
+	// known; any resuming shell may take them.
+	DefaultKey = "default"
 
-// defaultPath returns the default switch-target file path.
-func defaultPath() string {
+	// TTYEnv is the environment variable through which the hook tells
+	// `zp resume` which tty it runs on.
+	TTYEnv = "ZPICK_TTY"
+)
+
+// TTYKey returns the key for the client on tty, e.g. "tty-dev-pts-3" for
+// /dev/pts/3, or "" if tty isn't a device path.
+func TTYKey(tty string) string {
+	if !strings.HasPrefix(tty, "/") {
+		return ""
+	}
+	return "tty" + strings.ReplaceAll(tty, "/", "-")
+}
+
+// WriteKey returns the key a picker running inside a session writes its
+// target under: the client's tty if known (clientTTY), else DefaultKey.
+// The tty isn't known when the backend can't report it and no single
+// attach process names the session: a session attached from two
+// terminals, a zellij client that switched sessions, or a process list
+// that can't be read. Shells in every terminal may take a DefaultKey
+// target, so then the first one to prompt wins.
+func WriteKey(clientTTY string) string {
+	if k := TTYKey(clientTTY); k != "" {
+		return k
+	}
+	return DefaultKey
+}
+
+// ResumeKeys returns the keys a shell on tty may take a target from, the
+// most specific first.
+func ResumeKeys(tty string) []string {
+	if k := TTYKey(tty); k != "" {
+		return []string{k, DefaultKey}
+	}
+	return []string{DefaultKey}
+}
+
+// dirPath is the switch-target directory override.
+var dirPath string
+
+// defaultDir returns the default switch-target directory.
+func defaultDir() string {
 	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".cache", "zpick", "switch-target")
+	return filepath.Join(home, ".cache", "zpick", "switch")
 }
 
-// path returns the active file path, using the override if set.
-func path() string {
-	if filePath != "" {
-		return filePath
+// dir returns the active directory, using the override if set.
+func dir() string {
+	if dirPath != "" {
+		return dirPath
 	}
-	return defaultPath()
+	return defaultDir()
+}
+
+// SetDir overrides the switch-target directory (for testing).
+func SetDir(d string) {
+	dirPath = d
 }
 
-// SetPath overrides the switch-target file path (for testing).
-func SetPath(p string) {
-	filePath = p
+// Path returns the file a target for key is kept in.
+func Path(key string) string {
+	return filepath.Join(dir(), key)
 }
 
-// Write saves the switch target to disk as JSON.
-func Write(t Target) error {
-	p := path()
-	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+// LegacyPath returns the single target file that hooks installed before
+// targets were kept per client look for, ~/.cache/zpick/switch-target.
+func LegacyPath() string {
+	return filepath.Join(filepath.Dir(dir()), "switch-target")
+}
+
+// Write saves the switch target for the client with key as JSON. Each file
+// is written under a temporary name and renamed into place, so a resuming
+// shell never reads half of it.
+//
+// The target is also written to LegacyPath, so a shell whose hook predates
+// per-client targets still resumes until `zp install-hook` is run again.
+// A current hook's resume removes that copy when it takes the target.
+func Write(key string, t Target) error {
+	d := dir()
+	if err := os.MkdirAll(d, 0o700); err != nil {
 		return fmt.Errorf("switcher: mkdir: %w", err)
 	}
 	data, err := json.Marshal(t)
 	if err != nil {
 		return fmt.Errorf("switcher: marshal: %w", err)
 	}
-	if err := os.WriteFile(p, data, 0o600); err != nil {
+	if err := writeFile(Path(key), data); err != nil {
+		return err
+	}
+	return writeFile(LegacyPath(), data)
+}
+
+// writeFile replaces the file at p with data through a temporary file in
+// the same directory.
+func writeFile(p string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("switcher: write: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
 		return fmt.Errorf("switcher: write: %w", err)
 	}
 	return nil
@@ -56,28 +143,48 @@ func Write(t Target) error {
 // maxAge is the maximum age of a switch-target file before it's considered stale.
 const maxAge = 30 * time.Second
 
-// Read reads and deletes the switch target file.
-// Returns error if file is missing or stale (>30s old).
-func Read() (Target, error) {
-	p := path()
+// Read takes the target of the first of keys that has a fresh one, deleting
+// its file. Stale files (>30s old) of those keys are deleted too. It
+// returns an error if none of the keys has a target.
+func Read(keys ...string) (Target, error) {
+	err := fmt.Errorf("switcher: no target")
+	for _, key := range keys {
+		var t Target
+		if t, err = read(Path(key)); err == nil {
+			os.Remove(LegacyPath()) // taken; an old hook mustn't take it again
+			return t, nil
+		}
+	}
+	return Target{}, err
+}
 
-	info, err := os.Stat(p)
-	if err != nil {
+// ReadLegacy takes the target from LegacyPath, for a hook that predates
+// per-client targets. It has no way to tell terminals apart.
+func ReadLegacy() (Target, error) {
+	return read(LegacyPath())
+}
+
+func read(p string) (Target, error) {
+	// Renaming first claims the file: if two shells race for the default
+	// target, only one of them gets it.
+	claimed := p + fmt.Sprintf(".%d", os.Getpid())
+	if err := os.Rename(p, claimed); err != nil {
 		return Target{}, fmt.Errorf("switcher: %w", err)
 	}
+	defer os.Remove(claimed)
 
+	info, err := os.Stat(claimed)
+	if err != nil {
+		return Target{}, fmt.Errorf("switcher: %w", err)
+	}
 	if time.Since(info.ModTime()) > maxAge {
-		os.Remove(p)
 		return Target{}, fmt.Errorf("switcher: file is stale (older than %v)", maxAge)
 	}
 
-	data, err := os.ReadFile(p)
+	data, err := os.ReadFile(claimed)
 	if err != nil {
 		return Target{}, fmt.Errorf("switcher: read: %w", err)
 	}
-
-	os.Remove(p)
-
 	var t Target
 	if err := json.Unmarshal(data, &t); err != nil {
 		return Target{}, fmt.Errorf("switcher: unmarshal: %w", err)
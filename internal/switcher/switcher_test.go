@@ -2,23 +2,25 @@ package switcher
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 )
 
+const key = "tty-dev-pts-3"
+
 func TestWriteAndRead(t *testing.T) {
-	// Use a temp file so tests don't pollute the real cache.
-	tmp := t.TempDir()
-	path := tmp + "/switch-target"
-	SetPath(path)
+	// Use a temp dir so tests don't pollute the real cache.
+	SetDir(t.TempDir())
+	defer SetDir("")
 
 	want := Target{Action: "attach", Name: "work"}
-	if err := Write(want); err != nil {
+	if err := Write(key, want); err != nil {
 		t.Fatalf("Write: %v", err)
 	}
 
-	got, err := Read()
+	got, err := Read(key)
 	if err != nil {
 		t.Fatalf("Read: %v", err)
 	}
@@ -32,11 +34,11 @@ func TestWriteAndRead(t *testing.T) {
 }
 
 func TestReadDeletesFile(t *testing.T) {
-	tmp := t.TempDir()
-	p := tmp + "/switch-target"
-	SetPath(p)
+	SetDir(t.TempDir())
+	defer SetDir("")
+	p := Path(key)
 
-	if err := Write(Target{Action: "attach", Name: "dev"}); err != nil {
+	if err := Write(key, Target{Action: "attach", Name: "dev"}); err != nil {
 		t.Fatalf("Write: %v", err)
 	}
 
@@ -45,7 +47,7 @@ func TestReadDeletesFile(t *testing.T) {
 		t.Fatalf("file should exist after Write: %v", err)
 	}
 
-	if _, err := Read(); err != nil {
+	if _, err := Read(key); err != nil {
 		t.Fatalf("Read: %v", err)
 	}
 
@@ -56,11 +58,11 @@ func TestReadDeletesFile(t *testing.T) {
 }
 
 func TestReadStaleFile(t *testing.T) {
-	tmp := t.TempDir()
-	p := tmp + "/switch-target"
-	SetPath(p)
+	SetDir(t.TempDir())
+	defer SetDir("")
+	p := Path(key)
 
-	if err := Write(Target{Action: "attach", Name: "old"}); err != nil {
+	if err := Write(key, Target{Action: "attach", Name: "old"}); err != nil {
 		t.Fatalf("Write: %v", err)
 	}
 
@@ -70,7 +72,7 @@ func TestReadStaleFile(t *testing.T) {
 		t.Fatalf("Chtimes: %v", err)
 	}
 
-	_, err := Read()
+	_, err := Read(key)
 	if err == nil {
 		t.Fatal("expected error for stale file, got nil")
 	}
@@ -85,11 +87,10 @@ func TestReadStaleFile(t *testing.T) {
 }
 
 func TestReadMissingFile(t *testing.T) {
-	tmp := t.TempDir()
-	p := tmp + "/nonexistent"
-	SetPath(p)
+	SetDir(t.TempDir())
+	defer SetDir("")
 
-	_, err := Read()
+	_, err := Read(key)
 	if err == nil {
 		t.Fatal("expected error for missing file, got nil")
 	}
@@ -99,16 +100,15 @@ func TestReadMissingFile(t *testing.T) {
 }
 
 func TestWriteWithDir(t *testing.T) {
-	tmp := t.TempDir()
-	p := tmp + "/switch-target"
-	SetPath(p)
+	SetDir(t.TempDir())
+	defer SetDir("")
 
 	want := Target{Action: "new", Name: "project", Dir: "/home/user/project"}
-	if err := Write(want); err != nil {
+	if err := Write(key, want); err != nil {
 		t.Fatalf("Write: %v", err)
 	}
 
-	got, err := Read()
+	got, err := Read(key)
 	if err != nil {
 		t.Fatalf("Read: %v", err)
 	}
@@ -123,3 +123,99 @@ func TestWriteWithDir(t *testing.T) {
 		t.Errorf("Dir = %q, want %q", got.Dir, want.Dir)
 	}
 }
+
+func TestReadOnlyTakesOwnKeys(t *testing.T) {
+	SetDir(t.TempDir())
+	defer SetDir("")
+
+	if err := Write("tty-dev-pts-1", Target{Action: "attach", Name: "one"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := Write("tty-dev-pts-2", Target{Action: "attach", Name: "two"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, err := Read("tty-dev-pts-2", DefaultKey)
+	if err != nil || got.Name != "two" {
+		t.Fatalf("Read(pts-2) = %+v, %v; want two", got, err)
+	}
+	if _, err := Read("tty-dev-pts-2", DefaultKey); err == nil {
+		t.Error("second Read(pts-2) should find nothing")
+	}
+	if _, err := os.Stat(Path("tty-dev-pts-1")); err != nil {
+		t.Errorf("the other terminal's target should be left alone: %v", err)
+	}
+}
+
+func TestReadFallsBackToDefault(t *testing.T) {
+	SetDir(t.TempDir())
+	defer SetDir("")
+
+	if err := Write(DefaultKey, Target{Action: "new", Name: "any"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got, err := Read(key, "tty-dev-pts-8", DefaultKey)
+	if err != nil || got.Name != "any" {
+		t.Errorf("Read = %+v, %v; want the default target", got, err)
+	}
+}
+
+func TestWriteLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	SetDir(dir)
+	defer SetDir("")
+
+	if err := Write(key, Target{Action: "attach", Name: "dev"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != key {
+		t.Errorf("dir holds %v, want just %s", entries, key)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	if got := TTYKey("/dev/pts/3"); got != "tty-dev-pts-3" {
+		t.Errorf("TTYKey = %q", got)
+	}
+	if got := TTYKey("not a tty"); got != "" {
+		t.Errorf("TTYKey(not a tty) = %q, want empty", got)
+	}
+	if got := WriteKey("/dev/ttys001"); got != "tty-dev-ttys001" {
+		t.Errorf("WriteKey(tty) = %q", got)
+	}
+	if got := WriteKey(""); got != DefaultKey {
+		t.Errorf("WriteKey() = %q, want %q", got, DefaultKey)
+	}
+	want := []string{"tty-dev-pts-3", DefaultKey}
+	if got := ResumeKeys("/dev/pts/3"); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("ResumeKeys = %v, want %v", got, want)
+	}
+	if got := ResumeKeys(""); len(got) != 1 || got[0] != DefaultKey {
+		t.Errorf("ResumeKeys() = %v, want just %s", got, DefaultKey)
+	}
+}
+
+func TestLegacyTarget(t *testing.T) {
+	SetDir(filepath.Join(t.TempDir(), "switch"))
+	defer SetDir("")
+
+	// A shell with a hook from before per-client targets takes the copy.
+	if err := Write(key, Target{Action: "attach", Name: "old-hook"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got, err := ReadLegacy(); err != nil || got.Name != "old-hook" {
+		t.Errorf("ReadLegacy = %+v, %v; want the old-hook target", got, err)
+	}
+
+	// A current hook taking the keyed target removes the copy.
+	if err := Write(key, Target{Action: "attach", Name: "new-hook"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := Read(key); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if _, err := os.Stat(LegacyPath()); !os.IsNotExist(err) {
+		t.Error("legacy copy still exists after the target was taken")
+	}
+}